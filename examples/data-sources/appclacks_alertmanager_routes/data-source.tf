@@ -0,0 +1,23 @@
+data "appclacks_alertmanager_routes" "routes" {
+  label_selector = {
+    "env": "prod"
+  }
+  default_receiver = "default"
+  receiver {
+    name = "sre-pager"
+    match_labels = {
+      "team": "sre",
+      "severity": "critical"
+    }
+  }
+  receiver {
+    name = "sre"
+    match_labels = {
+      "team": "sre"
+    }
+  }
+}
+
+output "alertmanager_route" {
+  value = data.appclacks_alertmanager_routes.routes.route
+}
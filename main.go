@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"log"
+	"time"
 
 	"github.com/appclacks/terraform-provider-appclacks/provider"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
@@ -11,10 +14,33 @@ import (
 //
 //go:generate go run github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs generate --provider-name appclacks
 
+// providerAddr is the provider address in the Terraform registry
+const providerAddr = "registry.terraform.io/appclacks/appclacks"
+
+// tracingShutdownTimeout bounds the export of the remaining spans when the provider stops
+const tracingShutdownTimeout = 2 * time.Second
+
 func main() {
 	var debugMode bool
-	flag.BoolVar(&debugMode, "debug", false, "enable debug mode")
+	flag.BoolVar(&debugMode, "debug", false, "start the provider in debug mode, to attach a debugger like delve")
 	flag.Parse()
 
-	plugin.Serve(&plugin.ServeOpts{ProviderFunc: provider.Provider})
+	shutdownTracing, err := provider.ConfigureTracing(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// In debug mode, the provider keeps running and prints the
+	// TF_REATTACH_PROVIDERS value Terraform should use to reach it.
+	plugin.Serve(&plugin.ServeOpts{
+		GRPCProviderFunc: provider.ProviderServer,
+		ProviderAddr:     providerAddr,
+		Debug:            debugMode,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("[WARN] failed to export the traces: %s", err)
+	}
 }
@@ -0,0 +1,281 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	dsAlertmanagerRoutesLabelSelector   = "label_selector"
+	dsAlertmanagerRoutesReceiver        = "receiver"
+	dsAlertmanagerRoutesReceiverName    = "name"
+	dsAlertmanagerRoutesMatchLabels     = "match_labels"
+	dsAlertmanagerRoutesDefaultReceiver = "default_receiver"
+	dsAlertmanagerRoutesNameLabel       = "healthcheck_name_label"
+	dsAlertmanagerRoutesRoute           = "route"
+	dsAlertmanagerRoutesUnmatched       = "unmatched_healthchecks"
+
+	defaultAlertmanagerNameLabel = "name"
+)
+
+var alertmanagerLabelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+var alertmanagerMatcherReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// alertmanagerRoute is an Alertmanager route, as written in the Alertmanager configuration file
+type alertmanagerRoute struct {
+	Receiver string              `yaml:"receiver,omitempty"`
+	Matchers []string            `yaml:"matchers,omitempty"`
+	Routes   []alertmanagerRoute `yaml:"routes,omitempty"`
+}
+
+// alertmanagerReceiver associates the health checks having all the match labels to an Alertmanager receiver
+type alertmanagerReceiver struct {
+	name        string
+	matchLabels map[string]string
+}
+
+func dataSourceAlertmanagerRoutes() *schema.Resource {
+	return &schema.Resource{
+		Description: "Render an Alertmanager route tree sending the alerts of the health checks to receivers, based on the health check labels",
+		Schema: map[string]*schema.Schema{
+			dsAlertmanagerRoutesLabelSelector: {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Only the health checks having all these labels are routed. All health checks are routed by default",
+			},
+			dsAlertmanagerRoutesReceiver: {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "Receivers of the health check alerts. A health check is routed to the first receiver whose labels all match the health check labels",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dsAlertmanagerRoutesReceiverName: {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+							Description:  "Alertmanager receiver name",
+						},
+						dsAlertmanagerRoutesMatchLabels: {
+							Type:             schema.TypeMap,
+							Elem:             &schema.Schema{Type: schema.TypeString},
+							Required:         true,
+							ValidateDiagFunc: validateAlertmanagerLabelNames,
+							Description:      "Labels the health checks should have to be routed to this receiver (example: team = \"sre\"). Keys must be valid Prometheus label names",
+						},
+					},
+				},
+			},
+			dsAlertmanagerRoutesDefaultReceiver: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				Description:  "Receiver of the root route. When unset, the root route has no receiver and should be nested in an existing route",
+			},
+			dsAlertmanagerRoutesNameLabel: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultAlertmanagerNameLabel,
+				ValidateFunc: validation.StringMatch(alertmanagerLabelNameRegexp, "should be a valid Prometheus label name"),
+				Description:  "Alert label containing the health check name. Defaults to `name`",
+			},
+			dsAlertmanagerRoutesRoute: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Alertmanager route tree, in YAML",
+			},
+			dsAlertmanagerRoutesUnmatched: {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+				Description: "Names of the selected health checks which match no receiver",
+			},
+		},
+
+		ReadContext: dataSourceAlertmanagerRoutesRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultTimeout),
+		},
+	}
+}
+
+func dataSourceAlertmanagerRoutesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutRead))
+	defer cancel()
+	client := GetAppclacksClient(meta)
+
+	result, err := client.ListHealthchecks(ctx)
+	if err != nil {
+		return client.diagnostics(err)
+	}
+
+	selector := expandStringMap(d.Get(dsAlertmanagerRoutesLabelSelector))
+	var healthchecks []goclient.Healthcheck
+	for _, healthcheck := range result.Result {
+		if labelsMatch(healthcheck.Labels, selector) {
+			healthchecks = append(healthchecks, healthcheck)
+		}
+	}
+
+	route, unmatched := buildAlertmanagerRoutes(
+		healthchecks,
+		expandAlertmanagerReceivers(d.Get(dsAlertmanagerRoutesReceiver)),
+		d.Get(dsAlertmanagerRoutesDefaultReceiver).(string),
+		d.Get(dsAlertmanagerRoutesNameLabel).(string),
+	)
+	content, err := renderAlertmanagerRoute(route)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(schema.HashString(content)))
+	if err := d.Set(dsAlertmanagerRoutesRoute, content); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set(dsAlertmanagerRoutesUnmatched, unmatched); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if len(unmatched) == 0 {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity:      diag.Warning,
+		Summary:       "Health checks without receiver",
+		Detail:        fmt.Sprintf("%d health check(s) match no receiver and are not routed: %s", len(unmatched), strings.Join(unmatched, ", ")),
+		AttributePath: cty.GetAttrPath(dsAlertmanagerRoutesReceiver),
+	}}
+}
+
+func expandAlertmanagerReceivers(v interface{}) []alertmanagerReceiver {
+	var receivers []alertmanagerReceiver
+	for _, item := range v.([]interface{}) {
+		receiver, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		receivers = append(receivers, alertmanagerReceiver{
+			name:        receiver[dsAlertmanagerRoutesReceiverName].(string),
+			matchLabels: expandStringMap(receiver[dsAlertmanagerRoutesMatchLabels]),
+		})
+	}
+	return receivers
+}
+
+// labelsMatch returns true if the labels contain all the selector labels
+func labelsMatch(labels map[string]string, selector map[string]string) bool {
+	for k, v := range selector {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// buildAlertmanagerRoutes returns the route tree sending the alerts of each health check
+// to the first receiver matching its labels, and the names of the unmatched health checks.
+// Each receiver route matches on the receiver labels and on the names of its health checks.
+func buildAlertmanagerRoutes(healthchecks []goclient.Healthcheck, receivers []alertmanagerReceiver, defaultReceiver string, nameLabel string) (alertmanagerRoute, []string) {
+	names := make([][]string, len(receivers))
+	unmatched := []string{}
+	for _, healthcheck := range healthchecks {
+		matched := false
+		for i, receiver := range receivers {
+			if labelsMatch(healthcheck.Labels, receiver.matchLabels) {
+				names[i] = append(names[i], healthcheck.Name)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, healthcheck.Name)
+		}
+	}
+	sort.Strings(unmatched)
+
+	root := alertmanagerRoute{Receiver: defaultReceiver}
+	for i, receiver := range receivers {
+		if len(names[i]) == 0 {
+			continue
+		}
+		sort.Strings(names[i])
+		route := alertmanagerRoute{Receiver: receiver.name}
+		for _, k := range sortedStringKeys(receiver.matchLabels) {
+			route.Matchers = append(route.Matchers, alertmanagerMatcher(k, "=", receiver.matchLabels[k]))
+		}
+		patterns := make([]string, len(names[i]))
+		for j, name := range names[i] {
+			patterns[j] = regexp.QuoteMeta(name)
+		}
+		route.Matchers = append(route.Matchers, alertmanagerMatcher(nameLabel, "=~", strings.Join(patterns, "|")))
+		root.Routes = append(root.Routes, route)
+	}
+	return root, unmatched
+}
+
+// renderAlertmanagerRoute renders the route in YAML, indented like the Alertmanager documentation examples
+func renderAlertmanagerRoute(route alertmanagerRoute) (string, error) {
+	var builder strings.Builder
+	encoder := yaml.NewEncoder(&builder)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(route); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// alertmanagerMatcher returns an Alertmanager matcher (example: team="sre")
+func alertmanagerMatcher(name string, operator string, value string) string {
+	return fmt.Sprintf(`%s%s"%s"`, name, operator, alertmanagerMatcherReplacer.Replace(value))
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateAlertmanagerLabelNames checks that the map keys are valid Prometheus label names
+func validateAlertmanagerLabelNames(i interface{}, path cty.Path) diag.Diagnostics {
+	m, ok := i.(map[string]interface{})
+	if !ok {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid type",
+			Detail:        "Expected a map",
+			AttributePath: path,
+		}}
+	}
+	var diags diag.Diagnostics
+	for _, k := range sortedKeys(m) {
+		if !alertmanagerLabelNameRegexp.MatchString(k) {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid label name",
+				Detail:        fmt.Sprintf("%s: %q is not a valid Prometheus label name", attributePathString(path.IndexString(k)), k),
+				AttributePath: path.IndexString(k),
+			})
+		}
+	}
+	return diags
+}
@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var testAccDataSourceAlertmanagerRoutesConfig = `
+resource "appclacks_healthcheck_tcp" "sre" {
+  name = "tf_acc_routes_sre"
+  labels = {
+    "team": "sre",
+    "source": "tf_acc_routes",
+  }
+  target = "appclacks.com"
+  port = 443
+}
+
+resource "appclacks_healthcheck_dns" "unmatched" {
+  name = "tf_acc_routes_unmatched"
+  labels = {
+    "team": "dev",
+    "source": "tf_acc_routes",
+  }
+  domain = "appclacks.com"
+}
+
+data "appclacks_alertmanager_routes" "routes" {
+  label_selector = {
+    "source": "tf_acc_routes",
+  }
+  default_receiver = "default"
+  receiver {
+    name = "sre-pager"
+    match_labels = {
+      "team": "sre",
+    }
+  }
+
+  depends_on = [
+    appclacks_healthcheck_tcp.sre,
+    appclacks_healthcheck_dns.unmatched,
+  ]
+}
+`
+
+func TestAccDataSourceAlertmanagerRoutes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceAlertmanagerRoutesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.appclacks_alertmanager_routes.routes", "route", `receiver: default
+routes:
+  - receiver: sre-pager
+    matchers:
+      - team="sre"
+      - name=~"tf_acc_routes_sre"
+`),
+					resource.TestCheckResourceAttr("data.appclacks_alertmanager_routes.routes", "unmatched_healthchecks.#", "1"),
+					resource.TestCheckResourceAttr("data.appclacks_alertmanager_routes.routes", "unmatched_healthchecks.0", "tf_acc_routes_unmatched"),
+				),
+			},
+		},
+	})
+}
+
+func TestBuildAlertmanagerRoutes(t *testing.T) {
+	healthchecks := []goclient.Healthcheck{
+		{Name: "api.appclacks.com", Labels: map[string]string{"team": "sre", "severity": "critical"}},
+		{Name: "website", Labels: map[string]string{"team": "sre"}},
+		{Name: "database", Labels: map[string]string{"team": "sre", "severity": "critical"}},
+		{Name: "frontend", Labels: map[string]string{"team": "web"}},
+		{Name: "batch", Labels: map[string]string{"team": "data"}},
+		{Name: "cron"},
+	}
+	receivers := []alertmanagerReceiver{
+		{name: "sre-pager", matchLabels: map[string]string{"team": "sre", "severity": "critical"}},
+		{name: "sre", matchLabels: map[string]string{"team": "sre"}},
+		{name: "web", matchLabels: map[string]string{"team": "web"}},
+		{name: "mobile", matchLabels: map[string]string{"team": "mobile"}},
+	}
+	route, unmatched := buildAlertmanagerRoutes(healthchecks, receivers, "default", "healthcheck")
+	expected := alertmanagerRoute{
+		Receiver: "default",
+		Routes: []alertmanagerRoute{
+			{
+				Receiver: "sre-pager",
+				Matchers: []string{`severity="critical"`, `team="sre"`, `healthcheck=~"api\\.appclacks\\.com|database"`},
+			},
+			{
+				Receiver: "sre",
+				Matchers: []string{`team="sre"`, `healthcheck=~"website"`},
+			},
+			{
+				Receiver: "web",
+				Matchers: []string{`team="web"`, `healthcheck=~"frontend"`},
+			},
+		},
+	}
+	if !reflect.DeepEqual(route, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, route)
+	}
+	if expected := []string{"batch", "cron"}; !reflect.DeepEqual(unmatched, expected) {
+		t.Fatalf("expected the unmatched health checks %v, got %v", expected, unmatched)
+	}
+}
+
+func TestAlertmanagerMatcher(t *testing.T) {
+	if matcher := alertmanagerMatcher("description", "=", "a \"quoted\" \\ value\n"); matcher != `description="a \"quoted\" \\ value\n"` {
+		t.Fatalf("unexpected matcher %s", matcher)
+	}
+}
+
+func TestValidateAlertmanagerLabelNames(t *testing.T) {
+	path := cty.GetAttrPath(dsAlertmanagerRoutesMatchLabels)
+	if diags := validateAlertmanagerLabelNames(map[string]interface{}{"team": "sre", "_env2": "prod"}, path); diags.HasError() {
+		t.Fatalf("unexpected error %v", diags)
+	}
+	diags := validateAlertmanagerLabelNames(map[string]interface{}{"team-name": "sre", "2env": "prod"}, path)
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 errors, got %v", diags)
+	}
+	if diags[0].Detail != `match_labels["2env"]: "2env" is not a valid Prometheus label name` {
+		t.Fatalf("unexpected error %s", diags[0].Detail)
+	}
+}
+
+func TestDataSourceAlertmanagerRoutesRead(t *testing.T) {
+	_, server := newFakeAPI(t)
+	c, err := goclient.New(goclient.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &Client{client: c, limiter: newLimiter(0, 0)}
+	ctx := context.Background()
+	for name, labels := range map[string]map[string]string{
+		"tf_acc_sre":   {"team": "sre", "env": "prod"},
+		"tf_acc_web":   {"team": "web", "env": "prod"},
+		"tf_acc_other": {"env": "prod"},
+		"tf_acc_dev":   {"team": "sre", "env": "dev"},
+	} {
+		_, err := client.CreateTCPHealthcheck(ctx, goclient.CreateTCPHealthcheckInput{
+			Name:     name,
+			Labels:   labels,
+			Interval: "60s",
+			Timeout:  "10s",
+			HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+				Target: "appclacks.com",
+				Port:   443,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := dataSourceAlertmanagerRoutes()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		dsAlertmanagerRoutesLabelSelector: map[string]interface{}{"env": "prod"},
+		dsAlertmanagerRoutesReceiver: []interface{}{
+			map[string]interface{}{
+				dsAlertmanagerRoutesReceiverName: "sre",
+				dsAlertmanagerRoutesMatchLabels:  map[string]interface{}{"team": "sre"},
+			},
+			map[string]interface{}{
+				dsAlertmanagerRoutesReceiverName: "web",
+				dsAlertmanagerRoutesMatchLabels:  map[string]interface{}{"team": "web"},
+			},
+		},
+	})
+	diags := dataSourceAlertmanagerRoutesRead(ctx, d, &providerConfig{client: client})
+	if diags.HasError() {
+		t.Fatalf("unexpected error %v", diags)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || !strings.Contains(diags[0].Detail, "tf_acc_other") {
+		t.Fatalf("expected a warning for the unmatched health check, got %v", diags)
+	}
+	expected := `routes:
+  - receiver: sre
+    matchers:
+      - team="sre"
+      - name=~"tf_acc_sre"
+  - receiver: web
+    matchers:
+      - team="web"
+      - name=~"tf_acc_web"
+`
+	if route := d.Get(dsAlertmanagerRoutesRoute).(string); route != expected {
+		t.Fatalf("expected the route\n%s\ngot\n%s", expected, route)
+	}
+	if unmatched := d.Get(dsAlertmanagerRoutesUnmatched).([]interface{}); !reflect.DeepEqual(unmatched, []interface{}{"tf_acc_other"}) {
+		t.Fatalf("unexpected unmatched health checks %v", unmatched)
+	}
+	if d.Id() == "" {
+		t.Fatal("expected an ID")
+	}
+}
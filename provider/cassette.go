@@ -0,0 +1,310 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	envCassetteMode = "APPCLACKS_CASSETTE_MODE"
+	envCassettePath = "APPCLACKS_CASSETTE"
+
+	cassetteModeRecord = "record"
+	cassetteModeReplay = "replay"
+
+	cassetteRedacted = "REDACTED"
+)
+
+// cassetteRedactedHeaders are the headers whose values are never written in a cassette
+var cassetteRedactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// cassetteRedactedFields are the payload fields whose values are never written in a cassette.
+// Unlike in the logs, the HTTP health check headers and body are kept: the provider reads
+// them back, so redacted values would produce a diff when the cassette is replayed.
+var cassetteRedactedFields = map[string]bool{
+	"password": true,
+	"secret":   true,
+	"token":    true,
+}
+
+// cassetteIgnoredHeaders are the transport headers which are not written in a cassette
+var cassetteIgnoredHeaders = map[string]bool{
+	"Accept-Encoding":  true,
+	"Connection":       true,
+	"Content-Encoding": true,
+	"Content-Length":   true,
+	"Date":             true,
+	"User-Agent":       true,
+}
+
+// cassette is a recording of the API traffic
+type cassette struct {
+	Interactions []cassetteInteraction `json:"interactions"`
+}
+
+type cassetteInteraction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// cassetteServer records the API traffic in a cassette, or replays a cassette
+type cassetteServer struct {
+	lock     sync.Mutex
+	mode     string
+	path     string
+	cassette cassette
+	used     []bool
+	client   *http.Client
+	upstream string
+}
+
+var cassetteServers = struct {
+	sync.Mutex
+	endpoints map[string]string
+}{endpoints: make(map[string]string)}
+
+// startCassetteServer starts a local server recording or replaying the API traffic
+// and returns its endpoint. The server is shared by the providers using the same cassette.
+func startCassetteServer(mode string, path string, upstream apiEndpointConfig) (string, error) {
+	cassetteServers.Lock()
+	defer cassetteServers.Unlock()
+	key := mode + ":" + path
+	if endpoint, ok := cassetteServers.endpoints[key]; ok {
+		return endpoint, nil
+	}
+	server, err := newCassetteServer(mode, path, upstream)
+	if err != nil {
+		return "", err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to start the cassette server: %w", err)
+	}
+	go http.Serve(listener, server) //nolint:errcheck
+	endpoint := "http://" + listener.Addr().String()
+	cassetteServers.endpoints[key] = endpoint
+	return endpoint, nil
+}
+
+func newCassetteServer(mode string, path string, upstream apiEndpointConfig) (*cassetteServer, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%s should be set when %s is set", envCassettePath, envCassetteMode)
+	}
+	server := &cassetteServer{
+		mode: mode,
+		path: path,
+	}
+	switch mode {
+	case cassetteModeRecord:
+		if upstream.endpoint == "" {
+			return nil, errors.New("the API endpoint should be configured to record a cassette")
+		}
+		transport, err := newAPITransport(upstream)
+		if err != nil {
+			return nil, err
+		}
+		server.client = &http.Client{Transport: transport}
+		server.upstream = strings.TrimSuffix(upstream.endpoint, "/")
+		if err := server.save(); err != nil {
+			return nil, err
+		}
+	case cassetteModeReplay:
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the cassette: %w", err)
+		}
+		if err := json.Unmarshal(content, &server.cassette); err != nil {
+			return nil, fmt.Errorf("failed to decode the cassette %s: %w", path, err)
+		}
+		server.used = make([]bool, len(server.cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("%s should be %s or %s, got %q", envCassetteMode, cassetteModeRecord, cassetteModeReplay, mode)
+	}
+	return server, nil
+}
+
+func (s *cassetteServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cassette: failed to read the request body: %s", err), http.StatusBadRequest)
+		return
+	}
+	request := cassetteRequest{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Query:   r.URL.RawQuery,
+		Headers: redactHeaders(r.Header),
+		Body:    redactBody(body),
+	}
+	var response cassetteResponse
+	if s.mode == cassetteModeRecord {
+		response, err = s.record(r, request, body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("cassette: %s", err), http.StatusBadGateway)
+			return
+		}
+	} else {
+		var ok bool
+		response, ok = s.replay(request)
+		if !ok {
+			http.Error(w, fmt.Sprintf("cassette: no interaction recorded for %s %s", request.Method, r.URL.RequestURI()), http.StatusNotImplemented)
+			return
+		}
+	}
+	for k, v := range response.Headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(response.Status)
+	_, _ = w.Write([]byte(response.Body))
+}
+
+// record forwards the request to the API and saves the interaction in the cassette
+func (s *cassetteServer) record(r *http.Request, request cassetteRequest, body []byte) (cassetteResponse, error) {
+	upstreamRequest, err := http.NewRequestWithContext(r.Context(), r.Method, s.upstream+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		return cassetteResponse{}, err
+	}
+	upstreamRequest.Header = r.Header.Clone()
+	// let the transport negotiate and decompress the response so the cassette is readable
+	upstreamRequest.Header.Del("Accept-Encoding")
+	upstreamResponse, err := s.client.Do(upstreamRequest)
+	if err != nil {
+		return cassetteResponse{}, err
+	}
+	defer upstreamResponse.Body.Close()
+	responseBody, err := io.ReadAll(upstreamResponse.Body)
+	if err != nil {
+		return cassetteResponse{}, err
+	}
+	response := cassetteResponse{
+		Status:  upstreamResponse.StatusCode,
+		Headers: redactHeaders(upstreamResponse.Header),
+		Body:    string(responseBody),
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	recorded := response
+	recorded.Body = redactBody(responseBody)
+	s.cassette.Interactions = append(s.cassette.Interactions, cassetteInteraction{
+		Request:  request,
+		Response: recorded,
+	})
+	if err := s.save(); err != nil {
+		return cassetteResponse{}, err
+	}
+	return response, nil
+}
+
+// replay returns the response of the first unused interaction matching the request.
+// When all the matching interactions were used, the last one is replayed again.
+func (s *cassetteServer) replay(request cassetteRequest) (cassetteResponse, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	last := -1
+	for i, interaction := range s.cassette.Interactions {
+		recorded := interaction.Request
+		if recorded.Method != request.Method || recorded.Path != request.Path || recorded.Query != request.Query || recorded.Body != request.Body {
+			continue
+		}
+		if !s.used[i] {
+			s.used[i] = true
+			return interaction.Response, true
+		}
+		last = i
+	}
+	if last == -1 {
+		return cassetteResponse{}, false
+	}
+	return s.cassette.Interactions[last].Response, true
+}
+
+// save writes the cassette. The file is replaced atomically so an interrupted
+// run always leaves a valid cassette.
+func (s *cassetteServer) save() error {
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s.cassette); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write the cassette: %w", err)
+	}
+	if _, err := tmp.Write(content.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write the cassette: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write the cassette: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// redactHeaders returns the headers to write in a cassette, without credentials
+func redactHeaders(headers http.Header) map[string]string {
+	var result map[string]string
+	for k, v := range headers {
+		key := http.CanonicalHeaderKey(k)
+		if cassetteIgnoredHeaders[key] {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string)
+		}
+		if cassetteRedactedHeaders[key] {
+			result[key] = cassetteRedacted
+			continue
+		}
+		result[key] = strings.Join(v, ", ")
+	}
+	return result
+}
+
+// redactBody returns the body to write in a cassette. JSON bodies are written
+// with sorted keys and their credentials are redacted. Other bodies are kept as is.
+func redactBody(body []byte) string {
+	var value any
+	if len(body) == 0 || json.Unmarshal(body, &value) != nil {
+		return string(body)
+	}
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(maskPayloadValue(value, cassetteRedactedFields, nil, cassetteRedacted)); err != nil {
+		return string(body)
+	}
+	return strings.TrimSuffix(content.String(), "\n")
+}
@@ -0,0 +1,346 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const testCassetteHealthcheckID = "d0e0a3bc-3a3a-4c4b-8c3e-2b8a7e0d1a11"
+
+// testCassetteClient returns a client replaying the cassette, without retries
+func testCassetteClient(t *testing.T, path string) *Client {
+	server, err := newCassetteServer(cassetteModeReplay, path, apiEndpointConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	client, err := goclient.New(goclient.WithEndpoint(httpServer.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{client: client, limiter: newLimiter(0, 0)}
+}
+
+// testCassetteProviderClient configures the provider with the cassette environment variables
+func testCassetteProviderClient(t *testing.T, config map[string]interface{}) *Client {
+	p := Provider()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(config)); diags.HasError() {
+		t.Fatalf("failed to configure the provider: %v", diags)
+	}
+	return GetAppclacksClient(p.Meta())
+}
+
+func TestCassetteRecordReplay(t *testing.T) {
+	_, server := newFakeAPI(t)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	config := map[string]interface{}{
+		"api_endpoint": server.URL,
+		"username":     "tf_acc",
+		"password":     "s3cr3t-password",
+		"max_retries":  0,
+	}
+	ctx := context.Background()
+	run := func(client *Client) (goclient.Healthcheck, goclient.Healthcheck, error) {
+		created, err := client.CreateTCPHealthcheck(ctx, goclient.CreateTCPHealthcheckInput{
+			Name:     "tf_acc_cassette",
+			Labels:   map[string]string{"team": "sre"},
+			Interval: "60s",
+			Timeout:  "10s",
+			Enabled:  true,
+			HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+				Target: "appclacks.com",
+				Port:   443,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fetched, err := client.GetHealthcheck(ctx, goclient.GetHealthcheckInput{Identifier: created.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.DeleteHealthcheck(ctx, goclient.DeleteHealthcheckInput{ID: created.ID}); err != nil {
+			t.Fatal(err)
+		}
+		_, err = client.GetHealthcheck(ctx, goclient.GetHealthcheckInput{Identifier: created.ID})
+		return created, fetched, err
+	}
+
+	t.Setenv(envCassetteMode, cassetteModeRecord)
+	t.Setenv(envCassettePath, path)
+	recordedCreated, recordedFetched, recordedErr := run(testCassetteProviderClient(t, config))
+	if !errors.Is(recordedErr, goclient.ErrNotFound) {
+		t.Fatalf("expected a not found error, got %v", recordedErr)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"s3cr3t-password", base64.StdEncoding.EncodeToString([]byte("tf_acc:s3cr3t-password"))} {
+		if strings.Contains(string(content), secret) {
+			t.Fatalf("the cassette contains the credentials:\n%s", content)
+		}
+	}
+	var recorded cassette
+	if err := json.Unmarshal(content, &recorded); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded.Interactions) != 5 {
+		t.Fatalf("expected 5 interactions, got %d", len(recorded.Interactions))
+	}
+	if auth := recorded.Interactions[0].Request.Headers["Authorization"]; auth != cassetteRedacted {
+		t.Fatalf("expected a redacted Authorization header, got %q", auth)
+	}
+
+	// the API is stopped so the replayed responses can only come from the cassette
+	server.Close()
+	t.Setenv(envCassetteMode, cassetteModeReplay)
+	replayedCreated, replayedFetched, replayedErr := run(testCassetteProviderClient(t, config))
+	if !reflect.DeepEqual(recordedCreated, replayedCreated) {
+		t.Fatalf("expected %+v, got %+v", recordedCreated, replayedCreated)
+	}
+	if !reflect.DeepEqual(recordedFetched, replayedFetched) {
+		t.Fatalf("expected %+v, got %+v", recordedFetched, replayedFetched)
+	}
+	if !errors.Is(replayedErr, goclient.ErrNotFound) {
+		t.Fatalf("expected a not found error, got %v", replayedErr)
+	}
+}
+
+func TestCassetteReplayUnknownRequest(t *testing.T) {
+	client := testCassetteClient(t, filepath.Join("testdata", "cassettes", "errors.json"))
+	_, err := client.GetHealthcheck(context.Background(), goclient.GetHealthcheckInput{Identifier: "unknown"})
+	apiErr, ok := parseAPIError(err)
+	if !ok {
+		t.Fatalf("expected an API error, got %v", err)
+	}
+	if apiErr.StatusCode != 501 || !strings.Contains(apiErr.Body, "no interaction recorded for GET /api/v1/healthcheck/unknown") {
+		t.Fatalf("unexpected error %+v", apiErr)
+	}
+}
+
+func TestCassetteReplayReusesLastInteraction(t *testing.T) {
+	client := testCassetteClient(t, filepath.Join("testdata", "cassettes", "errors.json"))
+	for i := 0; i < 3; i++ {
+		_, err := client.DeleteHealthcheck(context.Background(), goclient.DeleteHealthcheckInput{ID: testCassetteHealthcheckID})
+		if apiErr, ok := parseAPIError(err); !ok || apiErr.StatusCode != 401 {
+			t.Fatalf("expected a 401 error, got %v", err)
+		}
+	}
+}
+
+func TestSendRequestErrors(t *testing.T) {
+	client := testCassetteClient(t, filepath.Join("testdata", "cassettes", "errors.json"))
+	ctx := context.Background()
+
+	t.Run("404 on update", func(t *testing.T) {
+		_, err := client.UpdateTCPHealthcheck(ctx, goclient.UpdateTCPHealthcheckInput{
+			ID:       testCassetteHealthcheckID,
+			Name:     "tf_acc_tcp",
+			Interval: "60s",
+			Timeout:  "10s",
+			Enabled:  true,
+			HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+				Target: "appclacks.com",
+				Port:   443,
+			},
+		})
+		if !errors.Is(err, goclient.ErrNotFound) {
+			t.Fatalf("expected a not found error, got %v", err)
+		}
+	})
+
+	t.Run("non-JSON error body", func(t *testing.T) {
+		_, err := client.GetHealthcheck(ctx, goclient.GetHealthcheckInput{Identifier: testCassetteHealthcheckID})
+		apiErr, ok := parseAPIError(err)
+		if !ok {
+			t.Fatalf("expected an API error, got %v", err)
+		}
+		if apiErr.StatusCode != 502 {
+			t.Fatalf("expected status 502, got %d", apiErr.StatusCode)
+		}
+		if apiErr.Body != "<html><body><h1>502 Bad Gateway</h1></body></html>\n" {
+			t.Fatalf("unexpected body %q", apiErr.Body)
+		}
+		if !isRetryableError(err) {
+			t.Fatal("expected a retryable error")
+		}
+	})
+
+	t.Run("non-JSON success body", func(t *testing.T) {
+		_, err := client.ListHealthchecks(ctx)
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("expected a JSON syntax error, got %v", err)
+		}
+	})
+
+	t.Run("plain text authentication error", func(t *testing.T) {
+		_, err := client.DeleteHealthcheck(ctx, goclient.DeleteHealthcheckInput{ID: testCassetteHealthcheckID})
+		apiErr, ok := parseAPIError(err)
+		if !ok || apiErr.StatusCode != 401 || apiErr.Body != "Unauthorized\n" {
+			t.Fatalf("expected a 401 error, got %v", err)
+		}
+		if isRetryableError(err) {
+			t.Fatal("expected a non retryable error")
+		}
+	})
+}
+
+func TestResourceHealthcheckTCPUpdateNotFound(t *testing.T) {
+	client := testCassetteClient(t, filepath.Join("testdata", "cassettes", "errors.json"))
+	r := resourceHealthcheckTCP()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		resHealthcheckName:     "tf_acc_tcp",
+		resHealthcheckInterval: "60s",
+		resHealthcheckTimeout:  "10s",
+		resHealthcheckEnabled:  true,
+		"target":               "appclacks.com",
+		"port":                 443,
+	})
+	d.SetId(testCassetteHealthcheckID)
+	diags := resourceHealthcheckTCPUpdate(context.Background(), d, &providerConfig{client: client})
+	if !diags.HasError() || diags[0].Summary != goclient.ErrNotFound.Error() {
+		t.Fatalf("expected a not found error, got %v", diags)
+	}
+}
+
+func TestNewCassetteServerErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		mode     string
+		path     string
+		upstream apiEndpointConfig
+		err      string
+	}{
+		{
+			name: "missing path",
+			mode: cassetteModeReplay,
+			err:  "APPCLACKS_CASSETTE should be set when APPCLACKS_CASSETTE_MODE is set",
+		},
+		{
+			name: "unknown mode",
+			mode: "rewind",
+			path: filepath.Join(t.TempDir(), "cassette.json"),
+			err:  `APPCLACKS_CASSETTE_MODE should be record or replay, got "rewind"`,
+		},
+		{
+			name: "record without endpoint",
+			mode: cassetteModeRecord,
+			path: filepath.Join(t.TempDir(), "cassette.json"),
+			err:  "the API endpoint should be configured to record a cassette",
+		},
+		{
+			name: "replay without cassette",
+			mode: cassetteModeReplay,
+			path: filepath.Join(t.TempDir(), "cassette.json"),
+			err:  "failed to read the cassette",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := newCassetteServer(c.mode, c.path, c.upstream)
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected the error %q, got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestCassetteRecordReplayHTTPHealthcheck(t *testing.T) {
+	_, server := newFakeAPI(t)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	config := map[string]interface{}{
+		"api_endpoint": server.URL,
+		"username":     "tf_acc",
+		"password":     "s3cr3t-password",
+		"max_retries":  0,
+	}
+	r := resourceHealthcheckHTTP()
+	raw := map[string]interface{}{
+		resHealthcheckName:            "tf_acc_cassette_http",
+		resHealthcheckInterval:        "60s",
+		resHealthcheckTimeout:         "10s",
+		resHealthcheckEnabled:         true,
+		resHealthcheckTarget:          "appclacks.com",
+		resHealthcheckPort:            443,
+		resHealthcheckHTTPMethod:      "POST",
+		resHealthcheckHTTPProtocol:    "https",
+		resHealthcheckHTTPValidStatus: []interface{}{200},
+		resHealthcheckHTTPHeaders:     map[string]interface{}{"Authorization": "Bearer s3cr3t-token"},
+		resHealthcheckHTTPBody:        `{"api_key":"s3cr3t-body"}`,
+	}
+	create := func() *terraform.InstanceState {
+		p := Provider()
+		if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(config)); diags.HasError() {
+			t.Fatalf("failed to configure the provider: %v", diags)
+		}
+		d := schema.TestResourceDataRaw(t, r.Schema, raw)
+		if diags := resourceHealthcheckHTTPCreate(context.Background(), d, p.Meta()); diags.HasError() {
+			t.Fatalf("failed to create the health check: %v", diags)
+		}
+		diff, err := r.SimpleDiff(context.Background(), d.State(), terraform.NewResourceConfigShimmed(testRawConfig(t, r, raw), r.CoreConfigSchema()), p.Meta())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff != nil && len(diff.Attributes) > 0 {
+			t.Fatalf("expected an empty plan, got %v", diff.Attributes)
+		}
+		return d.State()
+	}
+
+	t.Setenv(envCassetteMode, cassetteModeRecord)
+	t.Setenv(envCassettePath, path)
+	recorded := create()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "s3cr3t-password") {
+		t.Fatalf("the cassette contains the credentials:\n%s", content)
+	}
+	// the health check headers and body are read back by the provider, so they are kept
+	for _, value := range []string{"Bearer s3cr3t-token", "s3cr3t-body"} {
+		if !strings.Contains(string(content), value) {
+			t.Fatalf("the cassette does not contain %q:\n%s", value, content)
+		}
+	}
+
+	// the API is stopped so the replayed responses can only come from the cassette
+	server.Close()
+	t.Setenv(envCassetteMode, cassetteModeReplay)
+	replayed := create()
+	if !reflect.DeepEqual(recorded.Attributes, replayed.Attributes) {
+		t.Fatalf("expected %v, got %v", recorded.Attributes, replayed.Attributes)
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	cases := map[string]string{
+		"":                  "",
+		"not json":          "not json",
+		`{"b":1,"a":"<b>"}`: `{"a":"<b>","b":1}`,
+		`{"name":"tf_acc","password":"s3cr3t","nested":[{"Token":"abc","port":1}]}`: `{"name":"tf_acc","nested":[{"Token":"REDACTED","port":1}],"password":"REDACTED"}`,
+		`{"headers":{"Authorization":"Bearer abc"},"body":"{}"}`:                    `{"body":"{}","headers":{"Authorization":"Bearer abc"}}`,
+	}
+	for body, expected := range cases {
+		if redacted := redactBody([]byte(body)); redacted != expected {
+			t.Fatalf("expected %s, got %s", expected, redacted)
+		}
+	}
+}
@@ -0,0 +1,558 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"syscall"
+	"time"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultMaxRetries   = 3
+	defaultRetryMaxWait = "30s"
+	defaultRetryMinWait = 1 * time.Second
+)
+
+var apiErrorRegexp = regexp.MustCompile(`^the API returned an error: status (\d+)\n`)
+
+// Client wraps the Appclacks API client. All the API calls made by the provider go through it.
+type Client struct {
+	client         *goclient.Client
+	limiter        *limiter
+	requestTimeout time.Duration
+	maxRetries     int
+	retryMinWait   time.Duration
+	retryMaxWait   time.Duration
+	// transport sends the requests of the go-client, with the provider TLS settings
+	transport http.RoundTripper
+	// secrets are masked in the logs
+	secrets []string
+	// credentialSource describes where the credentials were read from, for the authentication errors
+	credentialSource string
+}
+
+// apiError is an error returned by the Appclacks API
+type apiError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter is the duration to wait before the next attempt, from the Retry-After header
+	RetryAfter time.Duration
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("the API returned an error: status %d\n%s", e.StatusCode, e.Body)
+}
+
+// parseAPIError extracts the status code and the body from the errors returned
+// by the go-client for API error responses
+func parseAPIError(err error) (*apiError, bool) {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	match := apiErrorRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return nil, false
+	}
+	status, convErr := strconv.Atoi(match[1])
+	if convErr != nil {
+		return nil, false
+	}
+	return &apiError{
+		StatusCode: status,
+		Body:       err.Error()[len(match[0]):],
+	}, true
+}
+
+// isRetryableError returns true if the error is transient and the request can be sent again.
+// Requests which timed out because of the provider request_timeout are retryable. Other
+// transport errors, like invalid certificates or URLs, fail immediately.
+func isRetryableError(err error) bool {
+	if apiErr, ok := parseAPIError(err); ok {
+		switch apiErr.StatusCode {
+		case 429, 502, 503, 504:
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	// io.EOF is returned when the API closes the connection before sending the response
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// isAmbiguousError returns true if the request may have been processed by the API
+// despite the error
+func isAmbiguousError(err error) bool {
+	if apiErr, ok := parseAPIError(err); ok {
+		return apiErr.StatusCode == 502 || apiErr.StatusCode == 504
+	}
+	return isRetryableError(err)
+}
+
+// backoff returns the duration to wait before the next attempt, using an
+// exponential backoff with full jitter
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.retryMinWait << attempt
+	if wait <= 0 || wait > c.retryMaxWait {
+		wait = c.retryMaxWait
+	}
+	return time.Duration(rand.Int63n(int64(wait) + 1))
+}
+
+// retryWait returns the duration to wait before the next attempt: the backoff, or the
+// Retry-After duration of the API response when it is longer, at most retry_max_wait
+func (c *Client) retryWait(attempt int, err error) time.Duration {
+	wait := c.backoff(attempt)
+	if apiErr, ok := parseAPIError(err); ok && apiErr.RetryAfter > wait {
+		wait = apiErr.RetryAfter
+	}
+	if wait > c.retryMaxWait {
+		wait = c.retryMaxWait
+	}
+	return wait
+}
+
+// maxRetryWaits returns the longest total duration of the waits between the
+// attempts of an API call: max_retries waits of retry_max_wait
+func (c *Client) maxRetryWaits() time.Duration {
+	if c.maxRetries > 0 && c.retryMaxWait > math.MaxInt64/time.Duration(c.maxRetries) {
+		return math.MaxInt64
+	}
+	return time.Duration(c.maxRetries) * c.retryMaxWait
+}
+
+// retry executes the function, retrying it on transient errors
+func retry[T any](ctx context.Context, c *Client, operation apiOperation, f func(context.Context) (T, error)) (T, error) {
+	attempt := 0
+	for {
+		result, err := f(ctx)
+		if err == nil || ctx.Err() != nil || attempt >= c.maxRetries || !isRetryableError(err) {
+			return result, err
+		}
+		wait := c.retryWait(attempt, err)
+		// the operation would time out while waiting, so the error is returned right away
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			tflog.Debug(ctx, "Not retrying Appclacks API call, the operation timeout is reached before the next attempt", map[string]interface{}{
+				"operation": operation.Name,
+				"wait":      wait.String(),
+				"error":     err.Error(),
+			})
+			return result, err
+		}
+		attempt++
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+			attribute.String("appclacks.api.operation", operation.Name),
+			attribute.Int("attempt", attempt),
+			attribute.String("wait", wait.String()),
+			attribute.String("error", err.Error()),
+		))
+		tflog.Debug(ctx, "Retrying Appclacks API call", map[string]interface{}{
+			"operation": operation.Name,
+			"attempt":   attempt,
+			"wait":      wait.String(),
+			"error":     err.Error(),
+		})
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
+
+// call executes a single API call once allowed by the limiter.
+// The call is bounded by the provider request timeout, and logged in the API subsystem.
+func call[T any](ctx context.Context, c *Client, operation apiOperation, f func(context.Context) (T, error)) (result T, err error) {
+	ctx, span := startAPISpan(ctx, operation)
+	defer func() {
+		endAPISpan(span, err)
+	}()
+	release, err := c.limiter.wait(ctx, operation.Name)
+	if err != nil {
+		return result, err
+	}
+	defer release()
+	if c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
+	}
+	ctx = c.apiLogContext(ctx, operation)
+	installAPITransport()
+	exchange := &apiExchange{transport: c.transport, emptyFields: operation.EmptyFields}
+	ctx = context.WithValue(ctx, apiExchangeKey{}, exchange)
+	logAPIRequest(ctx, operation)
+	start := time.Now()
+	result, err = f(ctx)
+	if err != nil && exchange.retryAfter > 0 {
+		if apiErr, ok := parseAPIError(err); ok {
+			apiErr.RetryAfter = exchange.retryAfter
+			err = apiErr
+		}
+	}
+	logAPIResponse(ctx, operation, &result, err, time.Since(start))
+	return result, err
+}
+
+// do executes an idempotent API call, retrying it on transient errors
+func do[T any](ctx context.Context, c *Client, operation apiOperation, f func(context.Context) (T, error)) (T, error) {
+	return retry(ctx, c, operation, func(ctx context.Context) (T, error) {
+		return call(ctx, c, operation, f)
+	})
+}
+
+// createHealthcheck executes a health check creation. Creations are not idempotent
+// so after an ambiguous failure, the health check is looked up by name before
+// sending the request again to avoid duplicates.
+func (c *Client) createHealthcheck(ctx context.Context, operation apiOperation, name string, healthcheckType string, f func(context.Context) (goclient.Healthcheck, error)) (goclient.Healthcheck, error) {
+	ambiguous := false
+	return retry(ctx, c, operation, func(ctx context.Context) (goclient.Healthcheck, error) {
+		if ambiguous {
+			existing, err := call(ctx, c, getHealthcheckOperation(name), func(ctx context.Context) (goclient.Healthcheck, error) {
+				return c.client.GetHealthcheck(ctx, goclient.GetHealthcheckInput{
+					Identifier: name,
+				})
+			})
+			if err == nil {
+				if existing.Type != healthcheckType {
+					return goclient.Healthcheck{}, fmt.Errorf("a %s health check named %s already exists", existing.Type, name)
+				}
+				return existing, nil
+			}
+			if !errors.Is(err, goclient.ErrNotFound) {
+				return goclient.Healthcheck{}, err
+			}
+		}
+		result, err := call(ctx, c, operation, f)
+		if err != nil && isAmbiguousError(err) {
+			ambiguous = true
+		}
+		return result, err
+	})
+}
+
+// healthcheckUpdateEmptyFields are the optional fields of the health check update
+// payloads which the go-client omits when they are empty. They are sent with their
+// empty value, so the fields removed from the configuration are cleared.
+var healthcheckUpdateEmptyFields = map[string]map[string]any{
+	"command": {
+		"description": "",
+		"labels":      map[string]string{},
+		"arguments":   []string{},
+	},
+	"dns": {
+		"description":  "",
+		"labels":       map[string]string{},
+		"expected-ips": []string{},
+	},
+	"http": {
+		"description": "",
+		"labels":      map[string]string{},
+		"host":        "",
+		"query":       map[string]string{},
+		"body":        "",
+		"body-regexp": []string{},
+		"headers":     map[string]string{},
+		"path":        "",
+		"key":         "",
+		"cert":        "",
+		"cacert":      "",
+	},
+	"tcp": {
+		"description": "",
+		"labels":      map[string]string{},
+	},
+	"tls": {
+		"description": "",
+		"labels":      map[string]string{},
+		"key":         "",
+		"cert":        "",
+		"cacert":      "",
+		"server-name": "",
+	},
+}
+
+// The go-client sends the enabled field of the DNS, TCP, TLS and HTTP health check
+// creation payloads as "bool", which the API ignores. When the created health check
+// is not in the expected state, it is updated to apply the enabled field.
+
+func (c *Client) CreateDNSHealthcheck(ctx context.Context, input goclient.CreateDNSHealthcheckInput) (goclient.Healthcheck, error) {
+	result, err := c.createHealthcheck(ctx, apiOperation{
+		Name:   "CreateDNSHealthcheck",
+		Method: http.MethodPost,
+		Path:   "/api/v1/healthcheck/dns",
+		Body:   input,
+	}, input.Name, "dns", func(ctx context.Context) (goclient.Healthcheck, error) {
+		return c.client.CreateDNSHealthcheck(ctx, input)
+	})
+	if err != nil || result.Enabled == input.Enabled {
+		return result, err
+	}
+	return c.UpdateDNSHealthcheck(ctx, goclient.UpdateDNSHealthcheckInput{
+		ID:                       result.ID,
+		Name:                     input.Name,
+		Description:              input.Description,
+		Labels:                   input.Labels,
+		Interval:                 input.Interval,
+		Timeout:                  input.Timeout,
+		Enabled:                  input.Enabled,
+		HealthcheckDNSDefinition: input.HealthcheckDNSDefinition,
+	})
+}
+
+func (c *Client) UpdateDNSHealthcheck(ctx context.Context, input goclient.UpdateDNSHealthcheckInput) (goclient.Healthcheck, error) {
+	return do(ctx, c, apiOperation{
+		Name:        "UpdateDNSHealthcheck",
+		Method:      http.MethodPut,
+		Path:        fmt.Sprintf("/api/v1/healthcheck/dns/%s", input.ID),
+		Body:        input,
+		EmptyFields: healthcheckUpdateEmptyFields["dns"],
+	}, func(ctx context.Context) (goclient.Healthcheck, error) {
+		return c.client.UpdateDNSHealthcheck(ctx, input)
+	})
+}
+
+func (c *Client) CreateTCPHealthcheck(ctx context.Context, input goclient.CreateTCPHealthcheckInput) (goclient.Healthcheck, error) {
+	result, err := c.createHealthcheck(ctx, apiOperation{
+		Name:   "CreateTCPHealthcheck",
+		Method: http.MethodPost,
+		Path:   "/api/v1/healthcheck/tcp",
+		Body:   input,
+	}, input.Name, "tcp", func(ctx context.Context) (goclient.Healthcheck, error) {
+		return c.client.CreateTCPHealthcheck(ctx, input)
+	})
+	if err != nil || result.Enabled == input.Enabled {
+		return result, err
+	}
+	return c.UpdateTCPHealthcheck(ctx, goclient.UpdateTCPHealthcheckInput{
+		ID:                       result.ID,
+		Name:                     input.Name,
+		Description:              input.Description,
+		Labels:                   input.Labels,
+		Interval:                 input.Interval,
+		Timeout:                  input.Timeout,
+		Enabled:                  input.Enabled,
+		HealthcheckTCPDefinition: input.HealthcheckTCPDefinition,
+	})
+}
+
+func (c *Client) UpdateTCPHealthcheck(ctx context.Context, input goclient.UpdateTCPHealthcheckInput) (goclient.Healthcheck, error) {
+	return do(ctx, c, apiOperation{
+		Name:        "UpdateTCPHealthcheck",
+		Method:      http.MethodPut,
+		Path:        fmt.Sprintf("/api/v1/healthcheck/tcp/%s", input.ID),
+		Body:        input,
+		EmptyFields: healthcheckUpdateEmptyFields["tcp"],
+	}, func(ctx context.Context) (goclient.Healthcheck, error) {
+		return c.client.UpdateTCPHealthcheck(ctx, input)
+	})
+}
+
+func (c *Client) CreateTLSHealthcheck(ctx context.Context, input goclient.CreateTLSHealthcheckInput) (goclient.Healthcheck, error) {
+	result, err := c.createHealthcheck(ctx, apiOperation{
+		Name:   "CreateTLSHealthcheck",
+		Method: http.MethodPost,
+		Path:   "/api/v1/healthcheck/tls",
+		Body:   input,
+	}, input.Name, "tls", func(ctx context.Context) (goclient.Healthcheck, error) {
+		return c.client.CreateTLSHealthcheck(ctx, input)
+	})
+	if err != nil || result.Enabled == input.Enabled {
+		return result, err
+	}
+	return c.UpdateTLSHealthcheck(ctx, goclient.UpdateTLSHealthcheckInput{
+		ID:                       result.ID,
+		Name:                     input.Name,
+		Description:              input.Description,
+		Labels:                   input.Labels,
+		Interval:                 input.Interval,
+		Timeout:                  input.Timeout,
+		Enabled:                  input.Enabled,
+		HealthcheckTLSDefinition: input.HealthcheckTLSDefinition,
+	})
+}
+
+func (c *Client) UpdateTLSHealthcheck(ctx context.Context, input goclient.UpdateTLSHealthcheckInput) (goclient.Healthcheck, error) {
+	return do(ctx, c, apiOperation{
+		Name:        "UpdateTLSHealthcheck",
+		Method:      http.MethodPut,
+		Path:        fmt.Sprintf("/api/v1/healthcheck/tls/%s", input.ID),
+		Body:        input,
+		EmptyFields: healthcheckUpdateEmptyFields["tls"],
+	}, func(ctx context.Context) (goclient.Healthcheck, error) {
+		return c.client.UpdateTLSHealthcheck(ctx, input)
+	})
+}
+
+func (c *Client) CreateHTTPHealthcheck(ctx context.Context, input goclient.CreateHTTPHealthcheckInput) (goclient.Healthcheck, error) {
+	result, err := c.createHealthcheck(ctx, apiOperation{
+		Name:   "CreateHTTPHealthcheck",
+		Method: http.MethodPost,
+		Path:   "/api/v1/healthcheck/http",
+		Body:   input,
+	}, input.Name, "http", func(ctx context.Context) (goclient.Healthcheck, error) {
+		return c.client.CreateHTTPHealthcheck(ctx, input)
+	})
+	if err != nil || result.Enabled == input.Enabled {
+		return result, err
+	}
+	return c.UpdateHTTPHealthcheck(ctx, goclient.UpdateHTTPHealthcheckInput{
+		ID:                        result.ID,
+		Name:                      input.Name,
+		Description:               input.Description,
+		Labels:                    input.Labels,
+		Interval:                  input.Interval,
+		Timeout:                   input.Timeout,
+		Enabled:                   input.Enabled,
+		HealthcheckHTTPDefinition: input.HealthcheckHTTPDefinition,
+	})
+}
+
+func (c *Client) UpdateHTTPHealthcheck(ctx context.Context, input goclient.UpdateHTTPHealthcheckInput) (goclient.Healthcheck, error) {
+	return do(ctx, c, apiOperation{
+		Name:        "UpdateHTTPHealthcheck",
+		Method:      http.MethodPut,
+		Path:        fmt.Sprintf("/api/v1/healthcheck/http/%s", input.ID),
+		Body:        input,
+		EmptyFields: healthcheckUpdateEmptyFields["http"],
+	}, func(ctx context.Context) (goclient.Healthcheck, error) {
+		return c.client.UpdateHTTPHealthcheck(ctx, input)
+	})
+}
+
+func (c *Client) CreateCommandHealthcheck(ctx context.Context, input goclient.CreateCommandHealthcheckInput) (goclient.Healthcheck, error) {
+	return c.createHealthcheck(ctx, apiOperation{
+		Name:   "CreateCommandHealthcheck",
+		Method: http.MethodPost,
+		Path:   "/api/v1/healthcheck/command",
+		Body:   input,
+	}, input.Name, "command", func(ctx context.Context) (goclient.Healthcheck, error) {
+		return c.client.CreateCommandHealthcheck(ctx, input)
+	})
+}
+
+func (c *Client) UpdateCommandHealthcheck(ctx context.Context, input goclient.UpdateCommandHealthcheckInput) (goclient.Healthcheck, error) {
+	return do(ctx, c, apiOperation{
+		Name:        "UpdateCommandHealthcheck",
+		Method:      http.MethodPut,
+		Path:        fmt.Sprintf("/api/v1/healthcheck/command/%s", input.ID),
+		Body:        input,
+		EmptyFields: healthcheckUpdateEmptyFields["command"],
+	}, func(ctx context.Context) (goclient.Healthcheck, error) {
+		return c.client.UpdateCommandHealthcheck(ctx, input)
+	})
+}
+
+// getHealthcheckOperation describes the lookup of a health check by ID or name
+func getHealthcheckOperation(identifier string) apiOperation {
+	return apiOperation{
+		Name:   "GetHealthcheck",
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v1/healthcheck/%s", identifier),
+	}
+}
+
+func (c *Client) GetHealthcheck(ctx context.Context, input goclient.GetHealthcheckInput) (goclient.Healthcheck, error) {
+	return do(ctx, c, getHealthcheckOperation(input.Identifier), func(ctx context.Context) (goclient.Healthcheck, error) {
+		return c.client.GetHealthcheck(ctx, input)
+	})
+}
+
+func (c *Client) ListHealthchecks(ctx context.Context) (goclient.ListHealthchecksOutput, error) {
+	return do(ctx, c, apiOperation{
+		Name:   "ListHealthchecks",
+		Method: http.MethodGet,
+		Path:   "/api/v1/healthcheck",
+	}, func(ctx context.Context) (goclient.ListHealthchecksOutput, error) {
+		return c.client.ListHealthchecks(ctx)
+	})
+}
+
+func (c *Client) DeleteHealthcheck(ctx context.Context, input goclient.DeleteHealthcheckInput) (goclient.Response, error) {
+	return do(ctx, c, apiOperation{
+		Name:   "DeleteHealthcheck",
+		Method: http.MethodDelete,
+		Path:   fmt.Sprintf("/api/v1/healthcheck/%s", input.ID),
+	}, func(ctx context.Context) (goclient.Response, error) {
+		return c.client.DeleteHealthcheck(ctx, input)
+	})
+}
+
+func (c *Client) CabourotteDiscovery(ctx context.Context, input goclient.CabourotteDiscoveryInput) (goclient.CabourotteDiscoveryOutput, error) {
+	return do(ctx, c, apiOperation{
+		Name:   "CabourotteDiscovery",
+		Method: http.MethodGet,
+		Path:   "/cabourotte/discovery",
+		Body:   input,
+	}, func(ctx context.Context) (goclient.CabourotteDiscoveryOutput, error) {
+		return c.client.CabourotteDiscovery(ctx, input)
+	})
+}
+
+func (c *Client) CreateOrUpdatePushgatewayMetric(ctx context.Context, input goclient.CreateOrUpdatePushgatewayMetricInput) (goclient.Response, error) {
+	return do(ctx, c, apiOperation{
+		Name:   "CreateOrUpdatePushgatewayMetric",
+		Method: http.MethodPost,
+		Path:   "/api/v1/pushgateway",
+		Body:   input,
+	}, func(ctx context.Context) (goclient.Response, error) {
+		return c.client.CreateOrUpdatePushgatewayMetric(ctx, input)
+	})
+}
+
+// DeletePushgatewayMetric deletes a pushgateway metric. Deleting a metric which
+// does not exist anymore is not an error.
+func (c *Client) DeletePushgatewayMetric(ctx context.Context, input goclient.DeletePushgatewayMetricInput) (goclient.Response, error) {
+	result, err := do(ctx, c, apiOperation{
+		Name:   "DeletePushgatewayMetric",
+		Method: http.MethodDelete,
+		Path:   fmt.Sprintf("/api/v1/pushgateway/%s", input.Identifier),
+	}, func(ctx context.Context) (goclient.Response, error) {
+		return c.client.DeletePushgatewayMetric(ctx, input)
+	})
+	if errors.Is(err, goclient.ErrNotFound) {
+		return result, nil
+	}
+	return result, err
+}
+
+func (c *Client) ListPushgatewayMetrics(ctx context.Context) (goclient.ListPushgatewayMetricsOutput, error) {
+	return do(ctx, c, apiOperation{
+		Name:   "ListPushgatewayMetrics",
+		Method: http.MethodGet,
+		Path:   "/api/v1/pushgateway",
+	}, func(ctx context.Context) (goclient.ListPushgatewayMetricsOutput, error) {
+		return c.client.ListPushgatewayMetrics(ctx)
+	})
+}
+
+func (c *Client) DeleteAllPushgatewayMetrics(ctx context.Context) (goclient.Response, error) {
+	return do(ctx, c, apiOperation{
+		Name:   "DeleteAllPushgatewayMetrics",
+		Method: http.MethodDelete,
+		Path:   "/api/v1/pushgateway",
+	}, func(ctx context.Context) (goclient.Response, error) {
+		return c.client.DeleteAllPushgatewayMetrics(ctx)
+	})
+}
@@ -0,0 +1,464 @@
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := goclient.New(goclient.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{
+		client:       client,
+		maxRetries:   3,
+		retryMinWait: time.Millisecond,
+		retryMaxWait: 10 * time.Millisecond,
+	}
+}
+
+func TestParseAPIError(t *testing.T) {
+	err := fmt.Errorf("the API returned an error: status 429\n%s", `{"messages":["too many requests"]}`)
+	apiErr, ok := parseAPIError(err)
+	if !ok {
+		t.Fatal("expected an API error")
+	}
+	if apiErr.StatusCode != 429 {
+		t.Fatalf("expected status 429, got %d", apiErr.StatusCode)
+	}
+	if apiErr.Body != `{"messages":["too many requests"]}` {
+		t.Fatalf("unexpected body %s", apiErr.Body)
+	}
+	if _, ok := parseAPIError(errors.New("connection refused")); ok {
+		t.Fatal("expected a non API error")
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	var calls atomic.Int32
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"result":[]}`)
+	}))
+
+	if _, err := client.ListHealthchecks(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls.Load())
+	}
+}
+
+func TestClientRetryExhausted(t *testing.T) {
+	var calls atomic.Int32
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	_, err := client.ListHealthchecks(context.Background())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls.Load() != 4 {
+		t.Fatalf("expected 4 calls, got %d", calls.Load())
+	}
+}
+
+func TestClientNoRetry(t *testing.T) {
+	var calls atomic.Int32
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"messages":["invalid request"]}`)
+	}))
+
+	_, err := client.ListHealthchecks(context.Background())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 call, got %d", calls.Load())
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "rate limited",
+			err:      fmt.Errorf("the API returned an error: status 429\n"),
+			expected: true,
+		},
+		{
+			name: "bad request",
+			err:  fmt.Errorf("the API returned an error: status 400\n"),
+		},
+		{
+			name:     "timeout",
+			err:      &url.Error{Op: "Get", URL: "https://api.appclacks.com", Err: context.DeadlineExceeded},
+			expected: true,
+		},
+		{
+			name:     "connection refused",
+			err:      &url.Error{Op: "Get", URL: "https://api.appclacks.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}},
+			expected: true,
+		},
+		{
+			name:     "connection reset",
+			err:      &url.Error{Op: "Get", URL: "https://api.appclacks.com", Err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}},
+			expected: true,
+		},
+		{
+			name:     "connection closed",
+			err:      &url.Error{Op: "Get", URL: "https://api.appclacks.com", Err: io.EOF},
+			expected: true,
+		},
+		{
+			name:     "truncated response",
+			err:      io.ErrUnexpectedEOF,
+			expected: true,
+		},
+		{
+			name: "invalid certificate",
+			err:  &url.Error{Op: "Get", URL: "https://api.appclacks.com", Err: x509.UnknownAuthorityError{}},
+		},
+		{
+			name: "invalid URL",
+			err:  &url.Error{Op: "parse", URL: "://api.appclacks.com", Err: errors.New("missing protocol scheme")},
+		},
+		{
+			name: "not found",
+			err:  goclient.ErrNotFound,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if retryable := isRetryableError(c.err); retryable != c.expected {
+				t.Fatalf("expected %t, got %t for %v", c.expected, retryable, c.err)
+			}
+		})
+	}
+}
+
+func TestClientNoRetryInvalidCertificate(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":[]}`)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	// the TLS handshake errors are expected
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	// the certificate of the test server is not trusted by the client
+	c, err := goclient.New(goclient.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &Client{
+		client:       c,
+		limiter:      newLimiter(0, 0),
+		maxRetries:   3,
+		retryMinWait: time.Millisecond,
+		retryMaxWait: 10 * time.Millisecond,
+	}
+
+	_, err = client.ListHealthchecks(context.Background())
+	var certErr x509.UnknownAuthorityError
+	if !errors.As(err, &certErr) {
+		t.Fatalf("expected a certificate error, got %v", err)
+	}
+	if connections.Load() != 1 {
+		t.Fatalf("expected 1 connection, got %d", connections.Load())
+	}
+}
+
+func TestProviderTLSSettings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":[]}`)
+	}))
+	t.Cleanup(server.Close)
+	cacert := filepath.Join(t.TempDir(), "cacert.pem")
+	if err := os.WriteFile(cacert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the TLS settings are applied by the provider transport, not by the go-client
+	p := Provider()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"api_endpoint": server.URL,
+		"tls_cacert":   cacert,
+	})); diags.HasError() {
+		t.Fatalf("failed to configure the provider: %v", diags)
+	}
+	if _, err := GetAppclacksClient(p.Meta()).ListHealthchecks(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientCreateAmbiguousFailure(t *testing.T) {
+	var creations atomic.Int32
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/healthcheck/tcp":
+			// the health check is created but the response is lost
+			creations.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/healthcheck/tf_acc_tcp":
+			if creations.Load() == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, `{"id":"b6dd6bfc-8a75-11ed-a1eb-0242ac120002","name":"tf_acc_tcp","type":"tcp","interval":"60s","timeout":"10s","target":"appclacks.com","port":443}`)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	result, err := client.CreateTCPHealthcheck(context.Background(), goclient.CreateTCPHealthcheckInput{
+		Name:     "tf_acc_tcp",
+		Interval: "60s",
+		Timeout:  "10s",
+		HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+			Target: "appclacks.com",
+			Port:   443,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ID != "b6dd6bfc-8a75-11ed-a1eb-0242ac120002" {
+		t.Fatalf("unexpected health check ID %s", result.ID)
+	}
+	if creations.Load() != 1 {
+		t.Fatalf("expected 1 creation, got %d", creations.Load())
+	}
+}
+
+func TestClientCreateHealthcheckEnabled(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled %t", enabled), func(t *testing.T) {
+			api, _ := newFakeAPI(t)
+			var updates atomic.Int32
+			client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPut {
+					updates.Add(1)
+				}
+				api.ServeHTTP(w, r)
+			}))
+
+			result, err := client.CreateTCPHealthcheck(context.Background(), goclient.CreateTCPHealthcheckInput{
+				Name:     "tf_acc_tcp",
+				Interval: "60s",
+				Timeout:  "10s",
+				Enabled:  enabled,
+				HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+					Target: "appclacks.com",
+					Port:   443,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Enabled != enabled || api.healthchecks[result.ID].Enabled != enabled {
+				t.Fatalf("expected a health check with enabled %t, got %+v", enabled, result)
+			}
+			// the API ignores the enabled field sent by the go-client on creation
+			expectedUpdates := int32(0)
+			if enabled {
+				expectedUpdates = 1
+			}
+			if updates.Load() != expectedUpdates {
+				t.Fatalf("expected %d updates, got %d", expectedUpdates, updates.Load())
+			}
+		})
+	}
+}
+
+func TestClientRetryContextCanceled(t *testing.T) {
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	client.retryMinWait = time.Hour
+	client.retryMaxWait = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.ListHealthchecks(ctx); err == nil {
+		t.Fatal("expected an error")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("the retry did not respect the context cancellation")
+	}
+}
+
+func TestClientRetryDeadline(t *testing.T) {
+	var calls atomic.Int32
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	// the jittered wait is almost always longer than the operation deadline
+	client.retryMinWait = 1000 * time.Hour
+	client.retryMaxWait = 1000 * time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := client.ListHealthchecks(ctx)
+	if apiErr, ok := parseAPIError(err); !ok || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 error, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("the retry waited for the operation deadline")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 call, got %d", calls.Load())
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	cases := []struct {
+		name         string
+		retryAfter   string
+		retryMaxWait time.Duration
+		min          time.Duration
+		max          time.Duration
+	}{
+		{
+			name:         "honored",
+			retryAfter:   "1",
+			retryMaxWait: 5 * time.Second,
+			min:          time.Second,
+			max:          3 * time.Second,
+		},
+		{
+			name:         "capped by retry_max_wait",
+			retryAfter:   "3600",
+			retryMaxWait: 50 * time.Millisecond,
+			max:          time.Second,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			api, server := newFakeAPI(t)
+			api.throttled = 1
+			api.retryAfter = c.retryAfter
+			goClient, err := goclient.New(goclient.WithEndpoint(server.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &Client{
+				client:       goClient,
+				limiter:      newLimiter(0, 0),
+				maxRetries:   3,
+				retryMinWait: time.Millisecond,
+				retryMaxWait: c.retryMaxWait,
+			}
+
+			start := time.Now()
+			if _, err := client.ListHealthchecks(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(start); elapsed < c.min || elapsed > c.max {
+				t.Fatalf("expected a wait between %s and %s, got %s", c.min, c.max, elapsed)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 7, 15, 20, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		" 3 ":                           3 * time.Second,
+		"0":                             0,
+		"-1":                            0,
+		"soon":                          0,
+		"Mon, 15 Jul 2024 20:00:30 GMT": 30 * time.Second,
+		"Mon, 15 Jul 2024 19:59:00 GMT": 0,
+	}
+	for value, expected := range cases {
+		if wait := parseRetryAfter(value, now); wait != expected {
+			t.Fatalf("expected %s for %q, got %s", expected, value, wait)
+		}
+	}
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	var calls atomic.Int32
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		fmt.Fprint(w, `{"result":[]}`)
+	}))
+	client.requestTimeout = 50 * time.Millisecond
+
+	if _, err := client.ListHealthchecks(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls.Load())
+	}
+}
+
+func TestClientDeletePushgatewayMetricNotFound(t *testing.T) {
+	var calls atomic.Int32
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Method != http.MethodDelete || r.URL.Path != "/api/v1/pushgateway/tf_acc_metric" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	if _, err := client.DeletePushgatewayMetric(context.Background(), goclient.DeletePushgatewayMetricInput{
+		Identifier: "tf_acc_metric",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 call, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// apiFieldErrorRegexp matches the validation errors of the API, which validates the
+// payloads with go-playground/validator and the validate tags of the go-client input types
+// (example: Key: 'CreateHTTPHealthcheckInput.HealthcheckHTTPDefinition.ValidStatus[1]' Error:Field validation for 'ValidStatus[1]' failed on the 'max' tag)
+var apiFieldErrorRegexp = regexp.MustCompile(`^Key: '[^']*' Error:Field validation for '([A-Za-z0-9_]+)((?:\[[^\]]*\])*)' failed on the '[^']*' tag$`)
+
+var apiFieldIndexRegexp = regexp.MustCompile(`\[([^\]]*)\]`)
+
+// apiFieldAttributes maps the fields of the go-client input types to the health check attributes
+var apiFieldAttributes = map[string]string{
+	"Name":            resHealthcheckName,
+	"Description":     resHealthcheckDescription,
+	"Labels":          resHealthcheckLabels,
+	"Interval":        resHealthcheckInterval,
+	"Timeout":         resHealthcheckTimeout,
+	"Enabled":         resHealthcheckEnabled,
+	"Command":         resHealthcheckCommandCommand,
+	"Arguments":       resHealthcheckCommandArguments,
+	"Domain":          resHealthcheckDNSDomain,
+	"ExpectedIPs":     resHealthcheckDNSExpectedIPs,
+	"Target":          resHealthcheckTarget,
+	"Port":            resHealthcheckPort,
+	"ShouldFail":      resHealthcheckTCPShouldFail,
+	"Method":          resHealthcheckHTTPMethod,
+	"Redirect":        resHealthcheckHTTPRedirect,
+	"Body":            resHealthcheckHTTPBody,
+	"BodyRegexp":      resHealthcheckHTTPBodyRegexp,
+	"ValidStatus":     resHealthcheckHTTPValidStatus,
+	"Headers":         resHealthcheckHTTPHeaders,
+	"Query":           resHealthcheckHTTPQuery,
+	"Protocol":        resHealthcheckHTTPProtocol,
+	"Path":            resHealthcheckHTTPPath,
+	"Host":            resHealthcheckHTTPHost,
+	"Key":             resHealthcheckTLSKey,
+	"Cert":            resHealthcheckTLSCert,
+	"Cacert":          resHealthcheckTLSCacert,
+	"ServerName":      resHealthcheckTLSServerName,
+	"Insecure":        resHealthcheckTLSInsecure,
+	"ExpirationDelay": resHealthcheckTLSExpirationDelay,
+}
+
+// apiMapFields are the map fields of the go-client input types, indexed by their keys
+var apiMapFields = map[string]bool{
+	"Labels":  true,
+	"Headers": true,
+	"Query":   true,
+}
+
+// apiSetFields are the array fields of the go-client input types which are sets in the
+// resources. The API indexes follow the payload order, while the set elements have no
+// index and are ordered by their hash, so the paths of their errors stop at the attribute.
+var apiSetFields = map[string]bool{
+	"Arguments":   true,
+	"ExpectedIPs": true,
+	"BodyRegexp":  true,
+	"ValidStatus": true,
+}
+
+// apiErrorPayload is the body of the API error responses
+type apiErrorPayload struct {
+	Messages []string `json:"messages"`
+	Message  string   `json:"message"`
+}
+
+// credentialSource describes where the API credentials were read from, for the authentication errors.
+// The provider attributes take precedence over the environment variables, like in the go-client.
+func credentialSource(d *schema.ResourceData) string {
+	source := func(attribute string, env string) string {
+		if _, ok := d.GetOk(attribute); ok {
+			return fmt.Sprintf("the provider %s attribute", attribute)
+		}
+		if os.Getenv(env) != "" {
+			return fmt.Sprintf("the %s environment variable", env)
+		}
+		return ""
+	}
+	username := source("username", "APPCLACKS_USERNAME")
+	password := source("password", "APPCLACKS_PASSWORD")
+	switch {
+	case username == "" && password == "":
+		return ""
+	case password == "":
+		return username + " (no password configured)"
+	case username == "":
+		return password + " (no username configured)"
+	case username == "the provider username attribute" && password == "the provider password attribute":
+		return "the provider username and password attributes"
+	case username == "the APPCLACKS_USERNAME environment variable" && password == "the APPCLACKS_PASSWORD environment variable":
+		return "the APPCLACKS_USERNAME and APPCLACKS_PASSWORD environment variables"
+	}
+	return username + " and " + password
+}
+
+// diagnostics converts an error returned by the client into diagnostics. The
+// messages of the API error responses become separate diagnostics, pointing to
+// the offending attribute for the validation errors.
+func (c *Client) diagnostics(err error) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+	apiErr, ok := parseAPIError(err)
+	if !ok {
+		return diag.FromErr(err)
+	}
+	messages := apiErrorMessages(apiErr.Body)
+
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized:
+		detail := "No credentials are configured: set the provider username and password attributes, or the APPCLACKS_USERNAME and APPCLACKS_PASSWORD environment variables."
+		if c.credentialSource != "" {
+			detail = fmt.Sprintf("The Appclacks API rejected the credentials read from %s. Check the username and password.", c.credentialSource)
+		}
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Appclacks API authentication failed",
+			Detail:   apiErrorDetail(detail, messages),
+		}}
+	case http.StatusForbidden:
+		detail := "Anonymous requests are not allowed to perform this operation."
+		if c.credentialSource != "" {
+			detail = fmt.Sprintf("The credentials read from %s are not allowed to perform this operation.", c.credentialSource)
+		}
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Appclacks API permission denied",
+			Detail:   apiErrorDetail(detail, messages),
+		}}
+	}
+
+	if len(messages) == 0 {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Appclacks API error (status %d)", apiErr.StatusCode),
+			Detail:   strings.TrimSpace(apiErr.Body),
+		}}
+	}
+	var diags diag.Diagnostics
+	for _, message := range messages {
+		// the detail is always the full message of the API
+		diagnostic := diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Appclacks API error (status %d)", apiErr.StatusCode),
+			Detail:   message,
+		}
+		if path, ok := apiFieldErrorPath(message); ok {
+			diagnostic.Summary = fmt.Sprintf("Invalid %s", attributePathString(path))
+			diagnostic.AttributePath = path
+		}
+		diags = append(diags, diagnostic)
+	}
+	return diags
+}
+
+// apiErrorMessages returns the messages of an API error body. The body is
+// returned as is when it is not a JSON error payload.
+func apiErrorMessages(body string) []string {
+	var payload apiErrorPayload
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		if body = strings.TrimSpace(body); body != "" {
+			return []string{body}
+		}
+		return nil
+	}
+	messages := payload.Messages
+	if payload.Message != "" {
+		messages = append(messages, payload.Message)
+	}
+	return messages
+}
+
+func apiErrorDetail(detail string, messages []string) string {
+	if len(messages) == 0 {
+		return detail
+	}
+	return fmt.Sprintf("%s\n\nThe API returned: %s", detail, strings.Join(messages, "\n"))
+}
+
+// apiFieldErrorPath returns the path of the attribute of an API validation error
+func apiFieldErrorPath(message string) (cty.Path, bool) {
+	match := apiFieldErrorRegexp.FindStringSubmatch(message)
+	if match == nil {
+		return nil, false
+	}
+	attribute, ok := apiFieldAttributes[match[1]]
+	if !ok {
+		return nil, false
+	}
+	path := cty.GetAttrPath(attribute)
+	if apiSetFields[match[1]] {
+		return path, true
+	}
+	for _, index := range apiFieldIndexRegexp.FindAllStringSubmatch(match[2], -1) {
+		if i, err := strconv.ParseInt(index[1], 10, 64); err == nil && !apiMapFields[match[1]] {
+			path = path.IndexInt(int(i))
+		} else {
+			path = path.IndexString(index[1])
+		}
+	}
+	return path, true
+}
@@ -0,0 +1,361 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testAPIError(status int, body string) error {
+	return fmt.Errorf("the API returned an error: status %d\n%s", status, body)
+}
+
+func TestClientDiagnostics(t *testing.T) {
+	cases := []struct {
+		name             string
+		credentialSource string
+		err              error
+		expected         diag.Diagnostics
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name: "not an API error",
+			err:  goclient.ErrNotFound,
+			expected: diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Not found",
+			}},
+		},
+		{
+			name: "validation errors",
+			err: testAPIError(400, `{"messages":[`+
+				`"Key: 'CreateHTTPHealthcheckInput.HealthcheckHTTPDefinition.ValidStatus[0]' Error:Field validation for 'ValidStatus[0]' failed on the 'max' tag",`+
+				`"Key: 'CreateHTTPHealthcheckInput.Labels[1]' Error:Field validation for 'Labels[1]' failed on the 'min' tag",`+
+				`"Key: 'UpdateHTTPHealthcheckInput.ID' Error:Field validation for 'ID' failed on the 'uuid' tag",`+
+				`"Invalid timeout: the timeout should be lower than the interval"]}`),
+			expected: diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Invalid valid_status",
+					Detail:        "Key: 'CreateHTTPHealthcheckInput.HealthcheckHTTPDefinition.ValidStatus[0]' Error:Field validation for 'ValidStatus[0]' failed on the 'max' tag",
+					AttributePath: cty.GetAttrPath("valid_status"),
+				},
+				{
+					Severity:      diag.Error,
+					Summary:       `Invalid labels["1"]`,
+					Detail:        "Key: 'CreateHTTPHealthcheckInput.Labels[1]' Error:Field validation for 'Labels[1]' failed on the 'min' tag",
+					AttributePath: cty.GetAttrPath("labels").IndexString("1"),
+				},
+				{
+					Severity: diag.Error,
+					Summary:  "Appclacks API error (status 400)",
+					Detail:   "Key: 'UpdateHTTPHealthcheckInput.ID' Error:Field validation for 'ID' failed on the 'uuid' tag",
+				},
+				{
+					Severity: diag.Error,
+					Summary:  "Appclacks API error (status 400)",
+					Detail:   "Invalid timeout: the timeout should be lower than the interval",
+				},
+			},
+		},
+		{
+			name: "single message payload",
+			err:  testAPIError(409, `{"message":"A healthcheck named tf_acc_tcp already exists"}`),
+			expected: diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Appclacks API error (status 409)",
+				Detail:   "A healthcheck named tf_acc_tcp already exists",
+			}},
+		},
+		{
+			name: "non-JSON body",
+			err:  testAPIError(502, "<html>Bad Gateway</html>\n"),
+			expected: diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Appclacks API error (status 502)",
+				Detail:   "<html>Bad Gateway</html>",
+			}},
+		},
+		{
+			name: "empty body",
+			err:  testAPIError(500, ""),
+			expected: diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Appclacks API error (status 500)",
+			}},
+		},
+		{
+			name:             "authentication error",
+			credentialSource: "the APPCLACKS_USERNAME and APPCLACKS_PASSWORD environment variables",
+			err:              testAPIError(401, `{"messages":["Unauthorized"]}`),
+			expected: diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Appclacks API authentication failed",
+				Detail:   "The Appclacks API rejected the credentials read from the APPCLACKS_USERNAME and APPCLACKS_PASSWORD environment variables. Check the username and password.\n\nThe API returned: Unauthorized",
+			}},
+		},
+		{
+			name: "authentication error without credentials",
+			err:  testAPIError(401, ""),
+			expected: diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Appclacks API authentication failed",
+				Detail:   "No credentials are configured: set the provider username and password attributes, or the APPCLACKS_USERNAME and APPCLACKS_PASSWORD environment variables.",
+			}},
+		},
+		{
+			name:             "permission error",
+			credentialSource: "the provider username and password attributes",
+			err:              testAPIError(403, "Forbidden"),
+			expected: diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Appclacks API permission denied",
+				Detail:   "The credentials read from the provider username and password attributes are not allowed to perform this operation.\n\nThe API returned: Forbidden",
+			}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &Client{credentialSource: c.credentialSource}
+			diags := client.diagnostics(c.err)
+			if len(diags) != len(c.expected) {
+				t.Fatalf("expected %d diagnostics, got %v", len(c.expected), diags)
+			}
+			for i, expected := range c.expected {
+				d := diags[i]
+				if d.Severity != expected.Severity || d.Summary != expected.Summary || d.Detail != expected.Detail {
+					t.Fatalf("expected %+v, got %+v", expected, d)
+				}
+				if !d.AttributePath.Equals(expected.AttributePath) {
+					t.Fatalf("expected the path %s, got %s", attributePathString(expected.AttributePath), attributePathString(d.AttributePath))
+				}
+			}
+		})
+	}
+}
+
+func TestCredentialSource(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   map[string]interface{}
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "none",
+			expected: "",
+		},
+		{
+			name:     "provider attributes",
+			config:   map[string]interface{}{"username": "user", "password": "pass"},
+			env:      map[string]string{"APPCLACKS_USERNAME": "env-user"},
+			expected: "the provider username and password attributes",
+		},
+		{
+			name:     "environment variables",
+			env:      map[string]string{"APPCLACKS_USERNAME": "user", "APPCLACKS_PASSWORD": "pass"},
+			expected: "the APPCLACKS_USERNAME and APPCLACKS_PASSWORD environment variables",
+		},
+		{
+			name:     "mixed",
+			config:   map[string]interface{}{"password": "pass"},
+			env:      map[string]string{"APPCLACKS_USERNAME": "user"},
+			expected: "the APPCLACKS_USERNAME environment variable and the provider password attribute",
+		},
+		{
+			name:     "missing password",
+			config:   map[string]interface{}{"username": "user"},
+			expected: "the provider username attribute (no password configured)",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv("APPCLACKS_USERNAME", "")
+			t.Setenv("APPCLACKS_PASSWORD", "")
+			for k, v := range c.env {
+				t.Setenv(k, v)
+			}
+			d := schema.TestResourceDataRaw(t, Provider().Schema, c.config)
+			if source := credentialSource(d); source != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, source)
+			}
+		})
+	}
+}
+
+// TestClientDiagnosticsValidationCassette replays validation errors in the format
+// of go-playground/validator, produced by validating testValidationHTTPInput and
+// testValidationTCPInput with the validate tags of the go-client input types
+func TestClientDiagnosticsValidationCassette(t *testing.T) {
+	client := testCassetteClient(t, filepath.Join("testdata", "cassettes", "validation.json"))
+	ctx := context.Background()
+	check := func(diags diag.Diagnostics, expected []cty.Path) {
+		t.Helper()
+		if len(diags) != len(expected) {
+			t.Fatalf("expected %d diagnostics, got %v", len(expected), diags)
+		}
+		for i, path := range expected {
+			if !diags[i].AttributePath.Equals(path) {
+				t.Fatalf("expected the path %s, got %s", attributePathString(path), attributePathString(diags[i].AttributePath))
+			}
+			if !strings.HasPrefix(diags[i].Detail, "Key: '") {
+				t.Fatalf("expected the message of the API in the detail, got %q", diags[i].Detail)
+			}
+		}
+	}
+
+	_, err := client.CreateHTTPHealthcheck(ctx, testValidationHTTPInput)
+	check(client.diagnostics(err), []cty.Path{
+		cty.GetAttrPath(resHealthcheckLabels).IndexString("team"),
+		// valid_status is a set: the API index is not an index of the set
+		cty.GetAttrPath(resHealthcheckHTTPValidStatus),
+		cty.GetAttrPath(resHealthcheckHTTPMethod),
+	})
+	_, err = client.UpdateTCPHealthcheck(ctx, testValidationTCPInput)
+	check(client.diagnostics(err), []cty.Path{
+		nil,
+		cty.GetAttrPath(resHealthcheckPort),
+	})
+}
+
+// TestAPIFieldAttributes checks that the validation errors are mapped from
+// fields of the go-client input types, and to attributes of the resources
+func TestAPIFieldAttributes(t *testing.T) {
+	fields := make(map[string]bool)
+	var collect func(reflect.Type)
+	collect = func(typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.Anonymous {
+				collect(field.Type)
+				continue
+			}
+			fields[field.Name] = true
+		}
+	}
+	for _, input := range []any{
+		goclient.UpdateCommandHealthcheckInput{},
+		goclient.UpdateDNSHealthcheckInput{},
+		goclient.UpdateHTTPHealthcheckInput{},
+		goclient.UpdateTCPHealthcheckInput{},
+		goclient.UpdateTLSHealthcheckInput{},
+	} {
+		collect(reflect.TypeOf(input))
+	}
+	attributes := make(map[string]bool)
+	for _, name := range []string{"appclacks_healthcheck_command", "appclacks_healthcheck_dns", "appclacks_healthcheck_http", "appclacks_healthcheck_tcp", "appclacks_healthcheck_tls"} {
+		for attribute := range Provider().ResourcesMap[name].Schema {
+			attributes[attribute] = true
+		}
+	}
+	for field, attribute := range apiFieldAttributes {
+		if !fields[field] {
+			t.Fatalf("%s is not a field of the go-client input types", field)
+		}
+		if !attributes[attribute] {
+			t.Fatalf("%s is not a health check attribute", attribute)
+		}
+	}
+	for field := range apiMapFields {
+		if _, ok := apiFieldAttributes[field]; !ok {
+			t.Fatalf("the map field %s is not mapped to an attribute", field)
+		}
+	}
+	// the paths of the set attributes stop at the attribute, and only them
+	for field, attribute := range apiFieldAttributes {
+		set := false
+		for _, name := range []string{"appclacks_healthcheck_command", "appclacks_healthcheck_dns", "appclacks_healthcheck_http", "appclacks_healthcheck_tcp", "appclacks_healthcheck_tls"} {
+			if s, ok := Provider().ResourcesMap[name].Schema[attribute]; ok && s.Type == schema.TypeSet {
+				set = true
+			}
+		}
+		if set != apiSetFields[field] {
+			t.Fatalf("the field %s should be in apiSetFields: %t", field, set)
+		}
+	}
+}
+
+func TestAPIFieldErrorPath(t *testing.T) {
+	cases := map[string]cty.Path{
+		"Key: 'CreateTCPHealthcheckInput.Name' Error:Field validation for 'Name' failed on the 'max' tag":                                                        cty.GetAttrPath(resHealthcheckName),
+		"Key: 'CreateHTTPHealthcheckInput.HealthcheckHTTPDefinition.Headers[X-Api-Key]' Error:Field validation for 'Headers[X-Api-Key]' failed on the 'max' tag": cty.GetAttrPath(resHealthcheckHTTPHeaders).IndexString("X-Api-Key"),
+		// the errors of the set elements point to the set
+		"Key: 'CreateDNSHealthcheckInput.HealthcheckDNSDefinition.ExpectedIPs[2]' Error:Field validation for 'ExpectedIPs[2]' failed on the 'ip' tag":      cty.GetAttrPath(resHealthcheckDNSExpectedIPs),
+		"Key: 'CreateCommandHealthcheckInput.HealthcheckCommandDefinition.Arguments[0]' Error:Field validation for 'Arguments[0]' failed on the 'min' tag": cty.GetAttrPath(resHealthcheckCommandArguments),
+		"Key: 'UpdateTCPHealthcheckInput.ID' Error:Field validation for 'ID' failed on the 'uuid' tag":                                                     nil,
+		"Invalid interval: \"1 m\" is not a valid duration":                                                                                                nil,
+	}
+	for message, expected := range cases {
+		path, ok := apiFieldErrorPath(message)
+		if ok != (expected != nil) || !path.Equals(expected) {
+			t.Fatalf("expected the path %s for %q, got %s", attributePathString(expected), message, attributePathString(path))
+		}
+	}
+}
+
+func TestResourceHealthcheckTCPCreateValidationError(t *testing.T) {
+	_, server := newFakeAPI(t)
+	c, err := goclient.New(goclient.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := &providerConfig{client: &Client{client: c, limiter: newLimiter(0, 0)}}
+	r := resourceHealthcheckTCP()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		resHealthcheckName:     "tf_acc_tcp",
+		resHealthcheckInterval: "60s",
+		resHealthcheckTimeout:  "10s",
+		resHealthcheckTarget:   "appclacks.com",
+		resHealthcheckPort:     70000,
+	})
+	diags := resourceHealthcheckTCPCreate(context.Background(), d, meta)
+	if len(diags) != 1 {
+		t.Fatalf("expected one diagnostic, got %v", diags)
+	}
+	if !diags[0].AttributePath.Equals(cty.GetAttrPath(resHealthcheckPort)) {
+		t.Fatalf("expected a diagnostic on the port, got %+v", diags[0])
+	}
+	if diags[0].Summary != "Invalid port" {
+		t.Fatalf("unexpected summary %s", diags[0].Summary)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected no ID")
+	}
+}
+
+// testValidationHTTPInput and testValidationTCPInput are rejected by the validate
+// tags of the go-client input types
+var testValidationHTTPInput = goclient.CreateHTTPHealthcheckInput{
+	Name:     "tf_acc_http",
+	Interval: "60s",
+	Timeout:  "10s",
+	Labels:   map[string]string{"team": ""},
+	HealthcheckHTTPDefinition: goclient.HealthcheckHTTPDefinition{
+		ValidStatus: []uint{200, 1200},
+		Target:      "appclacks.com",
+		Method:      "PATCH",
+		Port:        443,
+		Protocol:    "https",
+	},
+}
+
+var testValidationTCPInput = goclient.UpdateTCPHealthcheckInput{
+	ID:       "tf_acc_tcp",
+	Name:     "tf_acc_tcp",
+	Interval: "60s",
+	Timeout:  "10s",
+	HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+		Target: "appclacks.com",
+		Port:   70000,
+	},
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// expandStringMap converts a map attribute value. Empty maps are returned as nil
+// so null and empty collections are sent the same way to the API.
+func expandStringMap(v interface{}) map[string]string {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v.(string)
+	}
+	return result
+}
+
+// expandStringSet converts a set of strings attribute value. Empty sets are returned as nil.
+func expandStringSet(v interface{}) []string {
+	set, ok := v.(*schema.Set)
+	if !ok || set.Len() == 0 {
+		return nil
+	}
+	result := make([]string, set.Len())
+	for i, v := range set.List() {
+		result[i] = v.(string)
+	}
+	return result
+}
+
+// expandUintSet converts a set of integers attribute value. Empty sets are returned as nil.
+func expandUintSet(v interface{}) []uint {
+	set, ok := v.(*schema.Set)
+	if !ok || set.Len() == 0 {
+		return nil
+	}
+	result := make([]uint, set.Len())
+	for i, v := range set.List() {
+		result[i] = uint(v.(int))
+	}
+	return result
+}
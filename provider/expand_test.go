@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandCollections(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceHealthcheckHTTP().Schema, map[string]interface{}{
+		resHealthcheckHTTPValidStatus: []interface{}{200, 201},
+		resHealthcheckHTTPBodyRegexp:  []interface{}{"foo"},
+		resHealthcheckHTTPQuery: map[string]interface{}{
+			"page": "1",
+		},
+	})
+
+	status := expandUintSet(d.Get(resHealthcheckHTTPValidStatus))
+	sort.Slice(status, func(i, j int) bool { return status[i] < status[j] })
+	if !reflect.DeepEqual(status, []uint{200, 201}) {
+		t.Fatalf("unexpected valid status %v", status)
+	}
+	if regexps := expandStringSet(d.Get(resHealthcheckHTTPBodyRegexp)); !reflect.DeepEqual(regexps, []string{"foo"}) {
+		t.Fatalf("unexpected body regexps %v", regexps)
+	}
+	if query := expandStringMap(d.Get(resHealthcheckHTTPQuery)); !reflect.DeepEqual(query, map[string]string{"page": "1"}) {
+		t.Fatalf("unexpected query %v", query)
+	}
+
+	// null and empty collections are both expanded to nil
+	d = schema.TestResourceDataRaw(t, resourceHealthcheckHTTP().Schema, map[string]interface{}{
+		resHealthcheckHTTPQuery: map[string]interface{}{},
+	})
+	if query := expandStringMap(d.Get(resHealthcheckHTTPQuery)); query != nil {
+		t.Fatalf("expected a nil query, got %v", query)
+	}
+	if headers := expandStringMap(d.Get(resHealthcheckHTTPHeaders)); headers != nil {
+		t.Fatalf("expected nil headers, got %v", headers)
+	}
+	if regexps := expandStringSet(d.Get(resHealthcheckHTTPBodyRegexp)); regexps != nil {
+		t.Fatalf("expected nil body regexps, got %v", regexps)
+	}
+	if status := expandUintSet(d.Get(resHealthcheckHTTPValidStatus)); status != nil {
+		t.Fatalf("expected nil valid status, got %v", status)
+	}
+}
@@ -0,0 +1,656 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/google/uuid"
+)
+
+var fakeAPIHealthcheckRegexp = regexp.MustCompile(`^/api/v1/healthcheck/(command|dns|http|tcp|tls)(?:/([^/]+))?$`)
+
+// fakeAPI is an in-memory implementation of the Appclacks API endpoints used by the provider
+type fakeAPI struct {
+	lock         sync.Mutex
+	healthchecks map[string]goclient.Healthcheck
+	metrics      map[string]goclient.PushgatewayMetric
+	// throttled is the number of next requests rejected with a 429 and the retryAfter header
+	throttled  int
+	retryAfter string
+}
+
+// newFakeAPI starts a fake Appclacks API, stopped at the end of the test
+func newFakeAPI(t *testing.T) (*fakeAPI, *httptest.Server) {
+	api := &fakeAPI{
+		healthchecks: make(map[string]goclient.Healthcheck),
+		metrics:      make(map[string]goclient.PushgatewayMetric),
+	}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	return api, server
+}
+
+func (api *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	if api.throttled > 0 {
+		api.throttled--
+		w.Header().Set("Retry-After", api.retryAfter)
+		fakeAPIMessage(w, http.StatusTooManyRequests, "Too many requests")
+		return
+	}
+
+	path := r.URL.Path
+	switch {
+	case path == "/cabourotte/discovery" && r.Method == http.MethodGet:
+		api.discovery(w, r)
+	case path == "/api/v1/healthcheck" && r.Method == http.MethodGet:
+		api.listHealthchecks(w)
+	case fakeAPIHealthcheckRegexp.MatchString(path) && (r.Method == http.MethodPost || r.Method == http.MethodPut):
+		match := fakeAPIHealthcheckRegexp.FindStringSubmatch(path)
+		api.saveHealthcheck(w, r, match[1], match[2])
+	case strings.HasPrefix(path, "/api/v1/healthcheck/") && r.Method == http.MethodGet:
+		api.getHealthcheck(w, strings.TrimPrefix(path, "/api/v1/healthcheck/"))
+	case strings.HasPrefix(path, "/api/v1/healthcheck/") && r.Method == http.MethodDelete:
+		api.deleteHealthcheck(w, strings.TrimPrefix(path, "/api/v1/healthcheck/"))
+	case path == "/api/v1/pushgateway" && r.Method == http.MethodPost:
+		api.saveMetric(w, r)
+	case path == "/api/v1/pushgateway" && r.Method == http.MethodGet:
+		api.listMetrics(w)
+	case path == "/api/v1/pushgateway" && r.Method == http.MethodDelete:
+		api.metrics = make(map[string]goclient.PushgatewayMetric)
+		fakeAPIMessage(w, http.StatusOK, "Metrics deleted")
+	case strings.HasPrefix(path, "/api/v1/pushgateway/") && r.Method == http.MethodDelete:
+		api.deleteMetric(w, strings.TrimPrefix(path, "/api/v1/pushgateway/"))
+	default:
+		fakeAPIMessage(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func fakeAPIMessage(w http.ResponseWriter, status int, messages ...string) {
+	fakeAPIJSON(w, status, goclient.Response{Messages: messages})
+}
+
+func fakeAPIJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// fakeAPIHealthcheckInput contains the fields shared by the health check creation and update payloads
+type fakeAPIHealthcheckInput struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Labels      map[string]string `json:"labels"`
+	Interval    string            `json:"interval"`
+	Timeout     string            `json:"timeout"`
+	Enabled     bool              `json:"enabled"`
+}
+
+func (api *fakeAPI) findHealthcheck(identifier string) (goclient.Healthcheck, bool) {
+	if healthcheck, ok := api.healthchecks[identifier]; ok {
+		return healthcheck, true
+	}
+	for _, healthcheck := range api.healthchecks {
+		if healthcheck.Name == identifier {
+			return healthcheck, true
+		}
+	}
+	return goclient.Healthcheck{}, false
+}
+
+func (api *fakeAPI) saveHealthcheck(w http.ResponseWriter, r *http.Request, healthcheckType string, id string) {
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		fakeAPIMessage(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON payload: %s", err))
+		return
+	}
+	// like a partial update, the fields missing from an update payload are unchanged
+	if existing, ok := api.healthchecks[id]; ok && r.Method == http.MethodPut {
+		merged, err := fakeAPIMergePayload(&existing, body)
+		if err != nil {
+			fakeAPIMessage(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON payload: %s", err))
+			return
+		}
+		body = merged
+	}
+	var input fakeAPIHealthcheckInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		fakeAPIMessage(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON payload: %s", err))
+		return
+	}
+
+	healthcheck := goclient.Healthcheck{
+		ID:          uuid.NewString(),
+		Name:        input.Name,
+		Description: input.Description,
+		Type:        healthcheckType,
+		Labels:      input.Labels,
+		Interval:    input.Interval,
+		Timeout:     input.Timeout,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+		Enabled:     input.Enabled,
+	}
+
+	var definition any
+	var err error
+	switch healthcheckType {
+	case "command":
+		var d goclient.HealthcheckCommandDefinition
+		err = json.Unmarshal(body, &d)
+		definition = d
+	case "dns":
+		var d goclient.HealthcheckDNSDefinition
+		err = json.Unmarshal(body, &d)
+		definition = d
+	case "http":
+		var d goclient.HealthcheckHTTPDefinition
+		err = json.Unmarshal(body, &d)
+		definition = d
+	case "tcp":
+		var d goclient.HealthcheckTCPDefinition
+		err = json.Unmarshal(body, &d)
+		definition = d
+	case "tls":
+		var d goclient.HealthcheckTLSDefinition
+		err = json.Unmarshal(body, &d)
+		definition = d
+	}
+	if err != nil {
+		fakeAPIMessage(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON payload: %s", err))
+		return
+	}
+	healthcheck.Definition = definition
+
+	if messages := fakeAPIValidateHealthcheck(healthcheck, r.Method == http.MethodPut); len(messages) != 0 {
+		fakeAPIMessage(w, http.StatusBadRequest, messages...)
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		existing, ok := api.healthchecks[id]
+		if !ok {
+			fakeAPIMessage(w, http.StatusNotFound, "Healthcheck not found")
+			return
+		}
+		if existing.Type != healthcheckType {
+			fakeAPIMessage(w, http.StatusBadRequest, fmt.Sprintf("Healthcheck %s is a %s health check", id, existing.Type))
+			return
+		}
+		healthcheck.ID = existing.ID
+		healthcheck.CreatedAt = existing.CreatedAt
+	}
+	if other, ok := api.findHealthcheck(healthcheck.Name); ok && other.ID != healthcheck.ID {
+		fakeAPIMessage(w, http.StatusConflict, fmt.Sprintf("A healthcheck named %s already exists", healthcheck.Name))
+		return
+	}
+
+	api.healthchecks[healthcheck.ID] = healthcheck
+	fakeAPIJSON(w, http.StatusOK, &healthcheck)
+}
+
+// fakeAPIMergePayload returns the fields of the payload, completed with the other fields of the health check
+func fakeAPIMergePayload(healthcheck *goclient.Healthcheck, payload []byte) ([]byte, error) {
+	current, err := json.Marshal(healthcheck)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(current, &fields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// fakeAPIHealthcheckTypes are the names of the go-client types of each health check type,
+// used in the validation errors
+var fakeAPIHealthcheckTypes = map[string]string{
+	"command": "Command",
+	"dns":     "DNS",
+	"http":    "HTTP",
+	"tcp":     "TCP",
+	"tls":     "TLS",
+}
+
+// fakeAPIFieldError formats a validation error like go-playground/validator, whose
+// tags the go-client input types carry (example: Key: 'CreateTCPHealthcheckInput.HealthcheckTCPDefinition.Port'
+// Error:Field validation for 'Port' failed on the 'max' tag)
+func fakeAPIFieldError(namespace string, field string, tag string) string {
+	return fmt.Sprintf("Key: '%s.%s' Error:Field validation for '%s' failed on the '%s' tag", namespace, field, field, tag)
+}
+
+// fakeAPIValidateHealthcheck implements the validation rules of the API. The
+// rules of the validate tags of the go-client input types return validator
+// errors, the other rules return plain messages.
+func fakeAPIValidateHealthcheck(healthcheck goclient.Healthcheck, update bool) []string {
+	var messages []string
+	typeName := fakeAPIHealthcheckTypes[healthcheck.Type]
+	input := fmt.Sprintf("Create%sHealthcheckInput", typeName)
+	if update {
+		input = fmt.Sprintf("Update%sHealthcheckInput", typeName)
+	}
+	definition := fmt.Sprintf("%s.Healthcheck%sDefinition", input, typeName)
+	invalid := func(format string, args ...any) {
+		messages = append(messages, fmt.Sprintf(format, args...))
+	}
+	field := func(namespace string, field string, tag string) {
+		messages = append(messages, fakeAPIFieldError(namespace, field, tag))
+	}
+	// length checks the "required,max=..." and "max=..." tags
+	length := func(namespace string, name string, value int, required bool, max int) {
+		switch {
+		case value == 0 && required:
+			field(namespace, name, "required")
+		case value > max:
+			field(namespace, name, "max")
+		}
+	}
+	length(input, "Name", len(healthcheck.Name), true, 255)
+	length(input, "Description", len(healthcheck.Description), false, 255)
+	for _, k := range sortedStringKeys(healthcheck.Labels) {
+		v := healthcheck.Labels[k]
+		switch {
+		case len(k) == 0 || len(v) == 0:
+			field(input, fmt.Sprintf("Labels[%s]", k), "min")
+		case len(k) > 255 || len(v) > 255:
+			field(input, fmt.Sprintf("Labels[%s]", k), "max")
+		}
+	}
+	interval, intervalErr := time.ParseDuration(healthcheck.Interval)
+	if healthcheck.Interval == "" {
+		field(input, "Interval", "required")
+	} else if intervalErr != nil {
+		invalid("Invalid interval: %q is not a valid duration", healthcheck.Interval)
+	}
+	timeout, timeoutErr := time.ParseDuration(healthcheck.Timeout)
+	if healthcheck.Timeout == "" {
+		field(input, "Timeout", "required")
+	} else if timeoutErr != nil {
+		invalid("Invalid timeout: %q is not a valid duration", healthcheck.Timeout)
+	} else if intervalErr == nil && timeout >= interval {
+		invalid("Invalid timeout: the timeout should be lower than the interval")
+	}
+	validatePort := func(port uint) {
+		length(definition, "Port", int(port), true, 65535)
+	}
+
+	switch d := healthcheck.Definition.(type) {
+	case goclient.HealthcheckCommandDefinition:
+		length(definition, "Command", len(d.Command), true, 512)
+	case goclient.HealthcheckDNSDefinition:
+		length(definition, "Domain", len(d.Domain), true, 255)
+		if len(d.ExpectedIPs) > 10 {
+			field(definition, "ExpectedIPs", "max")
+		}
+		for i, ip := range d.ExpectedIPs {
+			if net.ParseIP(ip) == nil {
+				field(definition, fmt.Sprintf("ExpectedIPs[%d]", i), "ip_addr")
+			}
+		}
+	case goclient.HealthcheckHTTPDefinition:
+		length(definition, "ValidStatus", len(d.ValidStatus), true, 20)
+		for i, status := range d.ValidStatus {
+			if status > 1000 {
+				field(definition, fmt.Sprintf("ValidStatus[%d]", i), "max")
+			}
+		}
+		length(definition, "Target", len(d.Target), true, 255)
+		switch d.Method {
+		case "GET", "POST", "PUT", "DELETE", "HEAD":
+		case "":
+			field(definition, "Method", "required")
+		default:
+			field(definition, "Method", "oneof")
+		}
+		validatePort(d.Port)
+		length(definition, "Query", len(d.Query), false, 20)
+		length(definition, "BodyRegexp", len(d.BodyRegexp), false, 3)
+		length(definition, "Headers", len(d.Headers), false, 20)
+		if d.Protocol != "http" && d.Protocol != "https" {
+			field(definition, "Protocol", "oneof")
+		}
+	case goclient.HealthcheckTCPDefinition:
+		if d.Target == "" {
+			field(definition, "Target", "required")
+		}
+		validatePort(d.Port)
+	case goclient.HealthcheckTLSDefinition:
+		if d.Target == "" {
+			field(definition, "Target", "required")
+		}
+		validatePort(d.Port)
+		if d.ExpirationDelay != "" {
+			if _, err := time.ParseDuration(d.ExpirationDelay); err != nil {
+				invalid("Invalid expiration delay: %q is not a valid duration", d.ExpirationDelay)
+			}
+		}
+	}
+	return messages
+}
+
+func (api *fakeAPI) sortedHealthchecks() []goclient.Healthcheck {
+	result := make([]goclient.Healthcheck, 0, len(api.healthchecks))
+	for _, healthcheck := range api.healthchecks {
+		result = append(result, healthcheck)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (api *fakeAPI) getHealthcheck(w http.ResponseWriter, identifier string) {
+	healthcheck, ok := api.findHealthcheck(identifier)
+	if !ok {
+		fakeAPIMessage(w, http.StatusNotFound, "Healthcheck not found")
+		return
+	}
+	fakeAPIJSON(w, http.StatusOK, &healthcheck)
+}
+
+func (api *fakeAPI) listHealthchecks(w http.ResponseWriter) {
+	result := make([]*goclient.Healthcheck, 0, len(api.healthchecks))
+	for _, healthcheck := range api.sortedHealthchecks() {
+		healthcheck := healthcheck
+		result = append(result, &healthcheck)
+	}
+	fakeAPIJSON(w, http.StatusOK, map[string]any{"result": result})
+}
+
+func (api *fakeAPI) deleteHealthcheck(w http.ResponseWriter, id string) {
+	if _, err := uuid.Parse(id); err != nil {
+		fakeAPIMessage(w, http.StatusBadRequest, fmt.Sprintf("Invalid field id: %s is not a valid UUID", id))
+		return
+	}
+	if _, ok := api.healthchecks[id]; !ok {
+		fakeAPIMessage(w, http.StatusNotFound, "Healthcheck not found")
+		return
+	}
+	delete(api.healthchecks, id)
+	fakeAPIMessage(w, http.StatusOK, "Healthcheck deleted")
+}
+
+func (api *fakeAPI) discovery(w http.ResponseWriter, r *http.Request) {
+	selector := make(map[string]string)
+	if labels := r.URL.Query().Get("labels"); labels != "" {
+		for _, label := range strings.Split(labels, ",") {
+			k, v, ok := strings.Cut(label, "=")
+			if !ok {
+				fakeAPIMessage(w, http.StatusBadRequest, fmt.Sprintf("Invalid labels selector %s", labels))
+				return
+			}
+			selector[k] = v
+		}
+	}
+	result := make(map[string][]*goclient.Healthcheck)
+	for _, healthcheck := range api.sortedHealthchecks() {
+		healthcheck := healthcheck
+		matches := healthcheck.Enabled
+		for k, v := range selector {
+			if healthcheck.Labels[k] != v {
+				matches = false
+			}
+		}
+		if matches {
+			key := fmt.Sprintf("%s-checks", healthcheck.Type)
+			result[key] = append(result[key], &healthcheck)
+		}
+	}
+	fakeAPIJSON(w, http.StatusOK, result)
+}
+
+func fakeAPIMetricKey(name string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k, v := range labels {
+		keys = append(keys, k+"="+v)
+	}
+	sort.Strings(keys)
+	return name + "{" + strings.Join(keys, ",") + "}"
+}
+
+func (api *fakeAPI) saveMetric(w http.ResponseWriter, r *http.Request) {
+	var input goclient.CreateOrUpdatePushgatewayMetricInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		fakeAPIMessage(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON payload: %s", err))
+		return
+	}
+	var messages []string
+	if len(input.Name) == 0 || len(input.Name) > 255 {
+		messages = append(messages, "Invalid field name: the name should be between 1 and 255 characters")
+	}
+	if input.Value == "" {
+		messages = append(messages, "Invalid field value: the value is required")
+	}
+	switch input.Type {
+	case "", "counter", "gauge", "histogram", "summary":
+	default:
+		messages = append(messages, "Invalid field type: the type should be one of counter, gauge, histogram, summary")
+	}
+	var expiresAt *time.Time
+	if input.TTL != "" {
+		ttl, err := time.ParseDuration(input.TTL)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("Invalid field ttl: %q is not a valid duration", input.TTL))
+		} else {
+			t := time.Now().UTC().Add(ttl).Truncate(time.Second)
+			expiresAt = &t
+		}
+	}
+	if len(messages) != 0 {
+		fakeAPIMessage(w, http.StatusBadRequest, messages...)
+		return
+	}
+
+	key := fakeAPIMetricKey(input.Name, input.Labels)
+	metric, ok := api.metrics[key]
+	if !ok {
+		metric = goclient.PushgatewayMetric{
+			ID:        uuid.NewString(),
+			CreatedAt: time.Now().UTC().Truncate(time.Second),
+		}
+	}
+	metric.Name = input.Name
+	metric.Description = input.Description
+	metric.Labels = input.Labels
+	metric.TTL = input.TTL
+	metric.Type = input.Type
+	metric.Value = input.Value
+	metric.ExpiresAt = expiresAt
+	api.metrics[key] = metric
+	fakeAPIMessage(w, http.StatusOK, "Metric created")
+}
+
+func (api *fakeAPI) listMetrics(w http.ResponseWriter) {
+	keys := make([]string, 0, len(api.metrics))
+	for k := range api.metrics {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	result := make([]goclient.PushgatewayMetric, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, api.metrics[k])
+	}
+	fakeAPIJSON(w, http.StatusOK, goclient.ListPushgatewayMetricsOutput{Result: result})
+}
+
+func (api *fakeAPI) deleteMetric(w http.ResponseWriter, identifier string) {
+	deleted := false
+	for k, metric := range api.metrics {
+		if metric.ID == identifier || metric.Name == identifier {
+			delete(api.metrics, k)
+			deleted = true
+		}
+	}
+	if !deleted {
+		fakeAPIMessage(w, http.StatusNotFound, "Metric not found")
+		return
+	}
+	fakeAPIMessage(w, http.StatusOK, "Metric deleted")
+}
+
+func TestFakeAPI(t *testing.T) {
+	_, server := newFakeAPI(t)
+	c, err := goclient.New(goclient.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &Client{client: c}
+	ctx := context.Background()
+
+	input := goclient.CreateTCPHealthcheckInput{
+		Name:     "tf_acc_tcp",
+		Labels:   map[string]string{"env": "prod"},
+		Interval: "60s",
+		Timeout:  "10s",
+		Enabled:  true,
+		HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+			Target: "appclacks.com",
+			Port:   443,
+		},
+	}
+	created, err := client.CreateTCPHealthcheck(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.CreatedAt.IsZero() || !created.Enabled {
+		t.Fatalf("unexpected health check %+v", created)
+	}
+	for _, identifier := range []string{created.ID, created.Name} {
+		result, err := client.GetHealthcheck(ctx, goclient.GetHealthcheckInput{Identifier: identifier})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ID != created.ID {
+			t.Fatalf("unexpected health check %+v", result)
+		}
+	}
+
+	if _, err := client.CreateTCPHealthcheck(ctx, input); err == nil || !strings.Contains(err.Error(), "status 409") {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	invalid := input
+	invalid.Name = "tf_acc_invalid"
+	invalid.Interval = "5s"
+	invalid.HealthcheckTCPDefinition.Port = 0
+	_, err = client.CreateTCPHealthcheck(ctx, invalid)
+	apiErr, ok := parseAPIError(err)
+	if !ok || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	for _, message := range []string{"Invalid timeout", "Field validation for 'Port' failed on the 'required' tag"} {
+		if !strings.Contains(apiErr.Body, message) {
+			t.Fatalf("expected %q in %s", message, apiErr.Body)
+		}
+	}
+
+	updated, err := client.UpdateTCPHealthcheck(ctx, goclient.UpdateTCPHealthcheckInput{
+		ID:       created.ID,
+		Name:     "tf_acc_tcp2",
+		Interval: "30s",
+		Timeout:  "5s",
+		Enabled:  true,
+		HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+			Target: "appclacks.fr",
+			Port:   80,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID != created.ID || updated.Labels != nil || updated.Definition.(goclient.HealthcheckTCPDefinition).Port != 80 {
+		t.Fatalf("unexpected health check %+v", updated)
+	}
+	if _, err := client.UpdateDNSHealthcheck(ctx, goclient.UpdateDNSHealthcheckInput{
+		ID:       created.ID,
+		Name:     "tf_acc_tcp2",
+		Interval: "30s",
+		Timeout:  "5s",
+		HealthcheckDNSDefinition: goclient.HealthcheckDNSDefinition{
+			Domain: "appclacks.com",
+		},
+	}); err == nil {
+		t.Fatal("expected an error when changing the health check type")
+	}
+
+	if _, err := client.CreateDNSHealthcheck(ctx, goclient.CreateDNSHealthcheckInput{
+		Name:     "tf_acc_dns",
+		Labels:   map[string]string{"env": "staging"},
+		Interval: "60s",
+		Timeout:  "10s",
+		Enabled:  true,
+		HealthcheckDNSDefinition: goclient.HealthcheckDNSDefinition{
+			Domain: "appclacks.com",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	list, err := client.ListHealthchecks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Result) != 2 {
+		t.Fatalf("expected 2 health checks, got %d", len(list.Result))
+	}
+	discovery, err := client.CabourotteDiscovery(ctx, goclient.CabourotteDiscoveryInput{Labels: "env=staging"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(discovery.DNSChecks) != 1 || len(discovery.TCPChecks) != 0 {
+		t.Fatalf("unexpected discovery result %+v", discovery)
+	}
+
+	if _, err := client.DeleteHealthcheck(ctx, goclient.DeleteHealthcheckInput{ID: created.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetHealthcheck(ctx, goclient.GetHealthcheckInput{Identifier: created.ID}); !errors.Is(err, goclient.ErrNotFound) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+
+	metric := goclient.CreateOrUpdatePushgatewayMetricInput{
+		Name:   "tf_acc_metric",
+		Labels: map[string]string{"env": "prod"},
+		TTL:    "1h",
+		Type:   "gauge",
+		Value:  "1",
+	}
+	if _, err := client.CreateOrUpdatePushgatewayMetric(ctx, metric); err != nil {
+		t.Fatal(err)
+	}
+	metric.Value = "2"
+	if _, err := client.CreateOrUpdatePushgatewayMetric(ctx, metric); err != nil {
+		t.Fatal(err)
+	}
+	metric.Type = "foo"
+	if _, err := client.CreateOrUpdatePushgatewayMetric(ctx, metric); err == nil || !strings.Contains(err.Error(), "Invalid field type") {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	metrics, err := client.ListPushgatewayMetrics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics.Result) != 1 || metrics.Result[0].Value != "2" || metrics.Result[0].ExpiresAt == nil {
+		t.Fatalf("unexpected metrics %+v", metrics.Result)
+	}
+	if _, err := client.DeletePushgatewayMetric(ctx, goclient.DeletePushgatewayMetricInput{Identifier: "tf_acc_metric"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DeleteAllPushgatewayMetrics(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
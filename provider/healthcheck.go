@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"time"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	resHealthcheckID        = "healthcheck_id"
+	resHealthcheckType      = "type"
+	resHealthcheckCreatedAt = "created_at"
+)
+
+// setHealthcheckMetadata stores the computed attributes common to all health checks
+func setHealthcheckMetadata(d *schema.ResourceData, healthcheck *goclient.Healthcheck) error {
+	if err := d.Set(resHealthcheckID, healthcheck.ID); err != nil {
+		return err
+	}
+	if err := d.Set(resHealthcheckType, healthcheck.Type); err != nil {
+		return err
+	}
+	createdAt := ""
+	if !healthcheck.CreatedAt.IsZero() {
+		createdAt = healthcheck.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	return d.Set(resHealthcheckCreatedAt, createdAt)
+}
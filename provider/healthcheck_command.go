@@ -7,6 +7,7 @@ import (
 
 	goclient "github.com/appclacks/go-client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -30,22 +31,48 @@ func resourceHealthcheckCommand() *schema.Resource {
 				Description: "Health check description",
 			},
 			resHealthcheckLabels: {
+				Type:             schema.TypeMap,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				Optional:         true,
+				ValidateDiagFunc: validateLabels,
+				Description:      "Health check labels. Keys and values must be between 1 and 255 characters",
+			},
+			resHealthcheckLabelsAll: {
 				Type:        schema.TypeMap,
 				Elem:        &schema.Schema{Type: schema.TypeString},
-				Optional:    true,
-				Description: "Health check labels",
+				Computed:    true,
+				Description: "All labels of the health check, including the provider default labels",
 			},
-			resHealthcheckInterval: {
+			resHealthcheckID: {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     defaultHealthcheckInterval,
-				Description: "Health check interval (example: 30s)",
+				Computed:    true,
+				Description: "Health check ID",
 			},
-			resHealthcheckTimeout: {
+			resHealthcheckType: {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     defaultHealthcheckTimeout,
-				Description: "Health check timeout (example: 5s)",
+				Computed:    true,
+				Description: "Health check type",
+			},
+			resHealthcheckCreatedAt: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Health check creation date (RFC 3339)",
+			},
+			resHealthcheckInterval: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Description:      "Health check interval (example: 30s). Equivalent durations (1m and 60s) do not produce a diff. Defaults to the provider `healthcheck_defaults` value or 60s",
+			},
+			resHealthcheckTimeout: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Description:      "Health check timeout (example: 5s). Defaults to the provider `healthcheck_defaults` value or 10s",
 			},
 			resHealthcheckCommandCommand: {
 				Type:        schema.TypeString,
@@ -65,6 +92,13 @@ func resourceHealthcheckCommand() *schema.Resource {
 		UpdateContext: resourceHealthcheckCommandUpdate,
 		DeleteContext: resourceHealthcheckCommandDelete,
 
+		CustomizeDiff: customdiff.Sequence(
+			resourceHealthcheckLabelsCustomizeDiff,
+			resourceHealthcheckDefaultsCustomizeDiff("command"),
+			resourceHealthcheckDurationsCustomizeDiff,
+			resourceHealthcheckTimeoutsCustomizeDiff,
+		),
+
 		Importer: &schema.ResourceImporter{},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -77,59 +111,50 @@ func resourceHealthcheckCommand() *schema.Resource {
 }
 
 func resourceHealthcheckCommandUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutUpdate))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
-	update := goclient.UpdateCommandHealthcheckInput{
-		ID:       d.Id(),
-		Name:     d.Get(resHealthcheckName).(string),
-		Interval: d.Get(resHealthcheckInterval).(string),
-		Timeout:  d.Get(resHealthcheckTimeout).(string),
-		Enabled:  false,
-		HealthcheckCommandDefinition: goclient.HealthcheckCommandDefinition{
-			Command: d.Get(resHealthcheckCommandCommand).(string),
-		},
-	}
-
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		update.Description = v.(string)
-	}
-	if l, ok := d.GetOk(resHealthcheckLabels); ok {
-		labels := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			labels[k] = v.(string)
-		}
-		update.Labels = labels
-	}
-
-	if set, ok := d.Get(resHealthcheckCommandArguments).(*schema.Set); ok {
-		if l := set.Len(); l > 0 {
-			list := make([]string, l)
-			for i, v := range set.List() {
-				list[i] = v.(string)
-			}
-			update.HealthcheckCommandDefinition.Arguments = list
-
-		}
+	update, err := expandHealthcheckCommandUpdateInput(ctx, d, meta)
+	if err != nil {
+		return client.diagnostics(err)
 	}
 
 	if _, err := client.UpdateCommandHealthcheck(ctx, update); err != nil {
-		return diag.FromErr(err)
+		return client.diagnostics(err)
 	}
 
 	return resourceHealthcheckCommandRead(ctx, d, meta)
 }
 
+// expandHealthcheckCommandUpdateInput builds the payload updating the health check.
+// All optional fields are sent, so the fields removed from the configuration are cleared.
+func expandHealthcheckCommandUpdateInput(ctx context.Context, d *schema.ResourceData, meta interface{}) (goclient.UpdateCommandHealthcheckInput, error) {
+	labels, err := expandHealthcheckUpdateLabels(ctx, d, meta)
+	if err != nil {
+		return goclient.UpdateCommandHealthcheckInput{}, err
+	}
+	return goclient.UpdateCommandHealthcheckInput{
+		ID:                           d.Id(),
+		Name:                         d.Get(resHealthcheckName).(string),
+		Description:                  d.Get(resHealthcheckDescription).(string),
+		Labels:                       labels,
+		Interval:                     d.Get(resHealthcheckInterval).(string),
+		Timeout:                      d.Get(resHealthcheckTimeout).(string),
+		Enabled:                      false,
+		HealthcheckCommandDefinition: expandHealthcheckCommandDefinition(d),
+	}, nil
+}
+
 func resourceHealthcheckCommandDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
 	healthcheckID := d.Id()
 	_, err := client.DeleteHealthcheck(ctx, goclient.DeleteHealthcheckInput{ID: healthcheckID})
-	if err != nil {
-		return diag.FromErr(err)
+	if err != nil && !errors.Is(err, goclient.ErrNotFound) {
+		return client.diagnostics(err)
 	}
 
 	return nil
@@ -141,49 +166,38 @@ func resourceHealthcheckCommandCreate(ctx context.Context, d *schema.ResourceDat
 
 	client := GetAppclacksClient(meta)
 
-	healthcheck := goclient.CreateCommandHealthcheckInput{
-		Name:     d.Get(resHealthcheckName).(string),
-		Interval: d.Get(resHealthcheckInterval).(string),
-		Timeout:  d.Get(resHealthcheckTimeout).(string),
-		Enabled:  false,
-		HealthcheckCommandDefinition: goclient.HealthcheckCommandDefinition{
-			Command: d.Get(resHealthcheckCommandCommand).(string),
-		},
-	}
-
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		healthcheck.Description = v.(string)
-	}
-	if l, ok := d.GetOk(resHealthcheckLabels); ok {
-		labels := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			labels[k] = v.(string)
-		}
-		healthcheck.Labels = labels
+	result, err := client.CreateCommandHealthcheck(ctx, expandHealthcheckCommandCreateInput(d, meta))
+	if err != nil {
+		return client.diagnostics(err)
 	}
 
-	if set, ok := d.Get(resHealthcheckCommandArguments).(*schema.Set); ok {
-		if l := set.Len(); l > 0 {
-			list := make([]string, l)
-			for i, v := range set.List() {
-				list[i] = v.(string)
-			}
-			healthcheck.HealthcheckCommandDefinition.Arguments = list
+	d.SetId(result.ID)
+	return resourceHealthcheckCommandRead(ctx, d, meta)
+}
 
-		}
+// expandHealthcheckCommandCreateInput builds the payload creating the health check
+func expandHealthcheckCommandCreateInput(d *schema.ResourceData, meta interface{}) goclient.CreateCommandHealthcheckInput {
+	return goclient.CreateCommandHealthcheckInput{
+		Name:                         d.Get(resHealthcheckName).(string),
+		Description:                  d.Get(resHealthcheckDescription).(string),
+		Labels:                       expandHealthcheckLabels(d, meta),
+		Interval:                     d.Get(resHealthcheckInterval).(string),
+		Timeout:                      d.Get(resHealthcheckTimeout).(string),
+		Enabled:                      false,
+		HealthcheckCommandDefinition: expandHealthcheckCommandDefinition(d),
 	}
+}
 
-	result, err := client.CreateCommandHealthcheck(ctx, healthcheck)
-	if err != nil {
-		return diag.FromErr(err)
+// expandHealthcheckCommandDefinition builds the Command definition shared by the create and update payloads
+func expandHealthcheckCommandDefinition(d *schema.ResourceData) goclient.HealthcheckCommandDefinition {
+	return goclient.HealthcheckCommandDefinition{
+		Command:   d.Get(resHealthcheckCommandCommand).(string),
+		Arguments: expandStringSet(d.Get(resHealthcheckCommandArguments)),
 	}
-
-	d.SetId(result.ID)
-	return resourceHealthcheckCommandRead(ctx, d, meta)
 }
 
 func resourceHealthcheckCommandRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutRead))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
@@ -196,12 +210,12 @@ func resourceHealthcheckCommandRead(ctx context.Context, d *schema.ResourceData,
 			d.SetId("")
 			return nil
 		}
-		return diag.FromErr(err)
+		return client.diagnostics(err)
 	}
-	return diag.FromErr(resourceCommandHealthcheckApply(ctx, d, &result))
+	return diag.FromErr(resourceCommandHealthcheckApply(ctx, d, meta, &result))
 }
 
-func resourceCommandHealthcheckApply(_ context.Context, d *schema.ResourceData, healthcheck *goclient.Healthcheck) error {
+func resourceCommandHealthcheckApply(_ context.Context, d *schema.ResourceData, meta interface{}, healthcheck *goclient.Healthcheck) error {
 
 	if healthcheck.Type != "command" {
 		return fmt.Errorf("Invalid healthcheck type. Expecting command, got %s", healthcheck.Type)
@@ -211,21 +225,23 @@ func resourceCommandHealthcheckApply(_ context.Context, d *schema.ResourceData,
 		return err
 	}
 
-	if healthcheck.Description != "" {
-		if err := d.Set(resHealthcheckDescription, healthcheck.Description); err != nil {
-			return err
-		}
+	if err := setHealthcheckMetadata(d, healthcheck); err != nil {
+		return err
+	}
+
+	if err := d.Set(resHealthcheckDescription, healthcheck.Description); err != nil {
+		return err
 	}
 
-	if err := d.Set(resHealthcheckLabels, healthcheck.Labels); err != nil {
+	if err := setHealthcheckLabels(d, meta, healthcheck.Labels); err != nil {
 		return err
 	}
 
-	if err := d.Set(resHealthcheckInterval, healthcheck.Interval); err != nil {
+	if err := setDuration(d, resHealthcheckInterval, healthcheck.Interval); err != nil {
 		return err
 	}
 
-	if err := d.Set(resHealthcheckTimeout, healthcheck.Timeout); err != nil {
+	if err := setDuration(d, resHealthcheckTimeout, healthcheck.Timeout); err != nil {
 		return err
 	}
 
@@ -238,10 +254,8 @@ func resourceCommandHealthcheckApply(_ context.Context, d *schema.ResourceData,
 		return err
 	}
 
-	if len(definition.Arguments) != 0 {
-		if err := d.Set(resHealthcheckCommandArguments, definition.Arguments); err != nil {
-			return err
-		}
+	if err := d.Set(resHealthcheckCommandArguments, definition.Arguments); err != nil {
+		return err
 	}
 
 	return nil
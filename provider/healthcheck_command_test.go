@@ -3,11 +3,13 @@ package provider
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
 	goclient "github.com/appclacks/go-client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -38,6 +40,13 @@ resource "appclacks_healthcheck_command" "check_command" {
   command = "cat"
   arguments = ["/foo"]
 }
+`
+
+	testAccResourceHealthcheckCommandConfigRemoveOptional = `
+resource "appclacks_healthcheck_command" "check_command" {
+  name = "tf_acc_command2"
+  command = "cat"
+}
 `
 )
 
@@ -62,6 +71,9 @@ func TestAccResourceHealthcheckCommand(t *testing.T) {
 						"labels.check": validateString("command"),
 						"arguments.0":  validateString("/"),
 					}),
+					resource.TestCheckResourceAttr("appclacks_healthcheck_command.check_command", "type", "command"),
+					resource.TestCheckResourceAttrPair("appclacks_healthcheck_command.check_command", "healthcheck_id", "appclacks_healthcheck_command.check_command", "id"),
+					resource.TestCheckResourceAttrSet("appclacks_healthcheck_command.check_command", "created_at"),
 				),
 			},
 			{
@@ -99,6 +111,18 @@ func TestAccResourceHealthcheckCommand(t *testing.T) {
 						s[0].Attributes)
 				},
 			},
+			{
+				Config: testAccResourceHealthcheckCommandConfigRemoveOptional,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceCheckExists("appclacks_healthcheck_command.check_command", check),
+					testAccCheckResourceCheck(check),
+					testAccCheckResourceCommandCheckAttributes(testAttrs{
+						"description": validateString(""),
+					}),
+					resource.TestCheckNoResourceAttr("appclacks_healthcheck_command.check_command", "labels.%"),
+					resource.TestCheckNoResourceAttr("appclacks_healthcheck_command.check_command", "arguments.#"),
+				),
+			},
 		},
 	})
 }
@@ -176,3 +200,133 @@ func testAccCheckResourceCommandCheckDestroy(s *terraform.State) error {
 
 	return errors.New("Command Health check still exists")
 }
+
+func TestExpandHealthcheckCommandCreateInput(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected goclient.CreateCommandHealthcheckInput
+	}{
+		{
+			name: "all attributes",
+			raw: map[string]interface{}{
+				resHealthcheckName:             "tf_acc_command",
+				resHealthcheckDescription:      "foo",
+				resHealthcheckLabels:           map[string]interface{}{"env": "prod"},
+				resHealthcheckInterval:         "30s",
+				resHealthcheckTimeout:          "5s",
+				resHealthcheckCommandCommand:   "ls",
+				resHealthcheckCommandArguments: []interface{}{"-l"},
+			},
+			expected: goclient.CreateCommandHealthcheckInput{
+				Name:        "tf_acc_command",
+				Description: "foo",
+				Labels:      map[string]string{"env": "prod", "team": "sre"},
+				Interval:    "30s",
+				Timeout:     "5s",
+				HealthcheckCommandDefinition: goclient.HealthcheckCommandDefinition{
+					Command:   "ls",
+					Arguments: []string{"-l"},
+				},
+			},
+		},
+		{
+			name: "required attributes",
+			raw: map[string]interface{}{
+				resHealthcheckName:           "tf_acc_command",
+				resHealthcheckInterval:       "60s",
+				resHealthcheckTimeout:        "10s",
+				resHealthcheckCommandCommand: "ls",
+			},
+			expected: goclient.CreateCommandHealthcheckInput{
+				Name:     "tf_acc_command",
+				Labels:   map[string]string{"team": "sre"},
+				Interval: "60s",
+				Timeout:  "10s",
+				HealthcheckCommandDefinition: goclient.HealthcheckCommandDefinition{
+					Command: "ls",
+				},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceHealthcheckCommand().Schema, c.raw)
+			input := expandHealthcheckCommandCreateInput(d, testExpandMeta())
+			if !reflect.DeepEqual(input, c.expected) {
+				t.Fatalf("expected %+v, got %+v", c.expected, input)
+			}
+		})
+	}
+}
+
+func TestExpandHealthcheckCommandUpdateInput(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceHealthcheckCommand().Schema, map[string]interface{}{
+		resHealthcheckName:           "tf_acc_command",
+		resHealthcheckInterval:       "60s",
+		resHealthcheckTimeout:        "10s",
+		resHealthcheckCommandCommand: "ls",
+	})
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	input, err := expandHealthcheckCommandUpdateInput(context.Background(), d, &providerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := goclient.UpdateCommandHealthcheckInput{
+		ID:       "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:     "tf_acc_command",
+		Interval: "60s",
+		Timeout:  "10s",
+		HealthcheckCommandDefinition: goclient.HealthcheckCommandDefinition{
+			Command: "ls",
+		},
+	}
+	if !reflect.DeepEqual(input, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, input)
+	}
+}
+
+func TestResourceCommandHealthcheckApply(t *testing.T) {
+	r := resourceHealthcheckCommand()
+	d := r.TestResourceData()
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	err := resourceCommandHealthcheckApply(context.Background(), d, testExpandMeta(), &goclient.Healthcheck{
+		ID:          "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:        "tf_acc_command",
+		Description: "foo",
+		Labels:      map[string]string{"env": "prod", "team": "sre"},
+		Type:        "command",
+		Interval:    "30s",
+		Timeout:     "5s",
+		Enabled:     true,
+		CreatedAt:   time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+		Definition: goclient.HealthcheckCommandDefinition{
+			Command:   "ls",
+			Arguments: []string{"-l", "-a"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCheckResourceDataValues(t, r, d, map[string]interface{}{
+		resHealthcheckName:             "tf_acc_command",
+		resHealthcheckDescription:      "foo",
+		resHealthcheckLabels:           map[string]interface{}{"env": "prod"},
+		resHealthcheckLabelsAll:        map[string]interface{}{"env": "prod", "team": "sre"},
+		resHealthcheckID:               "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		resHealthcheckType:             "command",
+		resHealthcheckCreatedAt:        "2023-01-02T15:04:05Z",
+		resHealthcheckInterval:         "30s",
+		resHealthcheckTimeout:          "5s",
+		resHealthcheckCommandCommand:   "ls",
+		resHealthcheckCommandArguments: []interface{}{"-a", "-l"},
+	})
+
+	err = resourceCommandHealthcheckApply(context.Background(), d, testExpandMeta(), &goclient.Healthcheck{
+		Type:       "tcp",
+		Definition: goclient.HealthcheckTCPDefinition{},
+	})
+	if err == nil || err.Error() != "Invalid healthcheck type. Expecting command, got tcp" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
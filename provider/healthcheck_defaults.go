@@ -0,0 +1,221 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	providerHealthcheckDefaults  = "healthcheck_defaults"
+	defaultHealthcheckHTTPMethod = "GET"
+)
+
+var healthcheckTypes = []string{"command", "dns", "http", "tcp", "tls"}
+
+// healthcheckDefaults contains the default values applied to the health checks
+// of a given type when they are not set on the resource
+type healthcheckDefaults struct {
+	interval        string
+	timeout         string
+	method          string
+	headers         map[string]string
+	expirationDelay string
+}
+
+func healthcheckDefaultsSchema() *schema.Schema {
+	typeSchema := func(extra map[string]*schema.Schema) *schema.Schema {
+		s := map[string]*schema.Schema{
+			resHealthcheckInterval: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateDuration,
+			},
+			resHealthcheckTimeout: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateDuration,
+			},
+		}
+		for k, v := range extra {
+			s[k] = v
+		}
+		return &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: s,
+			},
+		}
+	}
+
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"command": typeSchema(nil),
+				"dns":     typeSchema(nil),
+				"tcp":     typeSchema(nil),
+				"http": typeSchema(map[string]*schema.Schema{
+					resHealthcheckHTTPMethod: {
+						Type:             schema.TypeString,
+						Optional:         true,
+						ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(healthcheckHTTPMethods, true)),
+					},
+					resHealthcheckHTTPHeaders: {
+						Type:             schema.TypeMap,
+						Elem:             &schema.Schema{Type: schema.TypeString},
+						Optional:         true,
+						ValidateDiagFunc: validateHTTPHeaders(maxHealthcheckHTTPHeaders),
+					},
+				}),
+				"tls": typeSchema(map[string]*schema.Schema{
+					resHealthcheckTLSExpirationDelay: {
+						Type:             schema.TypeString,
+						Optional:         true,
+						ValidateDiagFunc: validateDuration,
+					},
+				}),
+			},
+		},
+	}
+}
+
+// expandHealthcheckDefaults builds the health check defaults for each health check type
+// from the provider configuration
+func expandHealthcheckDefaults(d *schema.ResourceData) map[string]healthcheckDefaults {
+	result := make(map[string]healthcheckDefaults)
+	for _, healthcheckType := range healthcheckTypes {
+		defaults := healthcheckDefaults{
+			interval: defaultHealthcheckInterval,
+			timeout:  defaultHealthcheckTimeout,
+			headers:  make(map[string]string),
+		}
+		if healthcheckType == "http" {
+			defaults.method = defaultHealthcheckHTTPMethod
+		}
+		result[healthcheckType] = defaults
+	}
+	if d == nil {
+		return result
+	}
+
+	l, ok := d.GetOk(providerHealthcheckDefaults)
+	if !ok {
+		return result
+	}
+	blocks, ok := l.([]interface{})[0].(map[string]interface{})
+	if !ok {
+		return result
+	}
+	for _, healthcheckType := range healthcheckTypes {
+		block, ok := blocks[healthcheckType].([]interface{})
+		if !ok || len(block) == 0 {
+			continue
+		}
+		values, ok := block[0].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		defaults := result[healthcheckType]
+		if v, ok := values[resHealthcheckInterval].(string); ok && v != "" {
+			defaults.interval = v
+		}
+		if v, ok := values[resHealthcheckTimeout].(string); ok && v != "" {
+			defaults.timeout = v
+		}
+		if v, ok := values[resHealthcheckHTTPMethod].(string); ok && v != "" {
+			defaults.method = strings.ToUpper(v)
+		}
+		if v, ok := values[resHealthcheckTLSExpirationDelay].(string); ok && v != "" {
+			defaults.expirationDelay = v
+		}
+		if headers, ok := values[resHealthcheckHTTPHeaders].(map[string]interface{}); ok {
+			for k, v := range headers {
+				defaults.headers[http.CanonicalHeaderKey(k)] = v.(string)
+			}
+		}
+		result[healthcheckType] = defaults
+	}
+
+	return result
+}
+
+// resourceHealthcheckDefaultsCustomizeDiff sets the attributes not configured on
+// the resource to the provider defaults for this health check type, so the
+// effective values are visible in the plan
+func resourceHealthcheckDefaultsCustomizeDiff(healthcheckType string) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		var defaults healthcheckDefaults
+		if meta != nil {
+			defaults = getProviderConfig(meta).healthcheckDefaults[healthcheckType]
+		} else {
+			defaults = expandHealthcheckDefaults(nil)[healthcheckType]
+		}
+		config := d.GetRawConfig()
+		if config.IsNull() || !config.IsKnown() {
+			return nil
+		}
+
+		setDefault := func(key string, value string, equal func(string, string) bool) error {
+			if !config.GetAttr(key).IsNull() {
+				return nil
+			}
+			if equal(d.Get(key).(string), value) {
+				return nil
+			}
+			return d.SetNew(key, value)
+		}
+
+		if err := setDefault(resHealthcheckInterval, defaults.interval, durationsEqual); err != nil {
+			return err
+		}
+		if err := setDefault(resHealthcheckTimeout, defaults.timeout, durationsEqual); err != nil {
+			return err
+		}
+
+		switch healthcheckType {
+		case "http":
+			if err := setDefault(resHealthcheckHTTPMethod, defaults.method, strings.EqualFold); err != nil {
+				return err
+			}
+			configHeaders := config.GetAttr(resHealthcheckHTTPHeaders)
+			if !configHeaders.IsWhollyKnown() {
+				return nil
+			}
+			headers := make(map[string]string)
+			for k, v := range defaults.headers {
+				headers[k] = v
+			}
+			if !configHeaders.IsNull() {
+				for k, v := range configHeaders.AsValueMap() {
+					if !v.IsNull() {
+						headers[http.CanonicalHeaderKey(k)] = v.AsString()
+					}
+				}
+			}
+			if len(headers) > maxHealthcheckHTTPHeaders {
+				return fmt.Errorf("%s: at most %d headers are allowed including the provider default headers, got %d", resHealthcheckHTTPHeaders, maxHealthcheckHTTPHeaders, len(headers))
+			}
+			current := make(map[string]string)
+			for k, v := range d.Get(resHealthcheckHTTPHeaders).(map[string]interface{}) {
+				current[k] = v.(string)
+			}
+			if !reflect.DeepEqual(headers, current) {
+				return d.SetNew(resHealthcheckHTTPHeaders, headers)
+			}
+		case "tls":
+			return setDefault(resHealthcheckTLSExpirationDelay, defaults.expirationDelay, durationsEqual)
+		}
+
+		return nil
+	}
+}
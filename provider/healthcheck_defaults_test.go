@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandHealthcheckDefaults(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		providerHealthcheckDefaults: []interface{}{
+			map[string]interface{}{
+				"http": []interface{}{
+					map[string]interface{}{
+						resHealthcheckInterval: "30s",
+						resHealthcheckHTTPHeaders: map[string]interface{}{
+							"User-Agent": "appclacks",
+						},
+					},
+				},
+				"tls": []interface{}{
+					map[string]interface{}{
+						resHealthcheckInterval:           "5m",
+						resHealthcheckTLSExpirationDelay: "168h",
+					},
+				},
+			},
+		},
+	})
+
+	defaults := expandHealthcheckDefaults(d)
+	expectedHTTP := healthcheckDefaults{
+		interval: "30s",
+		timeout:  defaultHealthcheckTimeout,
+		method:   defaultHealthcheckHTTPMethod,
+		headers: map[string]string{
+			"User-Agent": "appclacks",
+		},
+	}
+	if !reflect.DeepEqual(defaults["http"], expectedHTTP) {
+		t.Fatalf("expected HTTP defaults %+v, got %+v", expectedHTTP, defaults["http"])
+	}
+	expectedTLS := healthcheckDefaults{
+		interval:        "5m",
+		timeout:         defaultHealthcheckTimeout,
+		headers:         map[string]string{},
+		expirationDelay: "168h",
+	}
+	if !reflect.DeepEqual(defaults["tls"], expectedTLS) {
+		t.Fatalf("expected TLS defaults %+v, got %+v", expectedTLS, defaults["tls"])
+	}
+	expectedTCP := healthcheckDefaults{
+		interval: defaultHealthcheckInterval,
+		timeout:  defaultHealthcheckTimeout,
+		headers:  map[string]string{},
+	}
+	if !reflect.DeepEqual(defaults["tcp"], expectedTCP) {
+		t.Fatalf("expected TCP defaults %+v, got %+v", expectedTCP, defaults["tcp"])
+	}
+}
+
+func TestResourceHealthcheckDefaultsCustomizeDiff(t *testing.T) {
+	defaults := expandHealthcheckDefaults(nil)
+	defaults["http"] = healthcheckDefaults{
+		interval: "30s",
+		timeout:  "5s",
+		method:   "HEAD",
+		headers: map[string]string{
+			"User-Agent": "appclacks",
+		},
+	}
+	meta := &providerConfig{
+		healthcheckDefaults: defaults,
+	}
+	diff, err := testResourceDiff(t, resourceHealthcheckHTTP(), map[string]interface{}{
+		resHealthcheckName:            "tf_acc_http",
+		resHealthcheckTarget:          "appclacks.com",
+		resHealthcheckPort:            443,
+		resHealthcheckTimeout:         "7s",
+		resHealthcheckHTTPValidStatus: []int{200},
+		resHealthcheckHTTPHeaders: map[string]string{
+			"content-type": "application/json",
+		},
+	}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		resHealthcheckInterval:   "30s",
+		resHealthcheckTimeout:    "7s",
+		resHealthcheckHTTPMethod: "HEAD",
+		"headers.Content-Type":   "application/json",
+		"headers.User-Agent":     "appclacks",
+	}
+	for k, v := range expected {
+		attr, ok := diff.Attributes[k]
+		if !ok {
+			t.Fatalf("attribute %s not found in the plan", k)
+		}
+		if attr.New != v {
+			t.Fatalf("expected %s for %s, got %s", v, k, attr.New)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	goclient "github.com/appclacks/go-client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -19,6 +20,8 @@ const (
 	resHealthcheckEnabled        = "enabled"
 	resHealthcheckDNSDomain      = "domain"
 	resHealthcheckDNSExpectedIPs = "expected_ips"
+
+	maxHealthcheckDNSExpectedIPs = 10
 )
 
 func resourceHealthcheckDNS() *schema.Resource {
@@ -36,22 +39,48 @@ func resourceHealthcheckDNS() *schema.Resource {
 				Description: "Health check description",
 			},
 			resHealthcheckLabels: {
+				Type:             schema.TypeMap,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				Optional:         true,
+				ValidateDiagFunc: validateLabels,
+				Description:      "Health check labels. Keys and values must be between 1 and 255 characters",
+			},
+			resHealthcheckLabelsAll: {
 				Type:        schema.TypeMap,
 				Elem:        &schema.Schema{Type: schema.TypeString},
-				Optional:    true,
-				Description: "Health check labels",
+				Computed:    true,
+				Description: "All labels of the health check, including the provider default labels",
 			},
-			resHealthcheckInterval: {
+			resHealthcheckID: {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     defaultHealthcheckInterval,
-				Description: "Health check interval (example: 30s)",
+				Computed:    true,
+				Description: "Health check ID",
 			},
-			resHealthcheckTimeout: {
+			resHealthcheckType: {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     defaultHealthcheckTimeout,
-				Description: "Health check timeout (example: 5s)",
+				Computed:    true,
+				Description: "Health check type",
+			},
+			resHealthcheckCreatedAt: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Health check creation date (RFC 3339)",
+			},
+			resHealthcheckInterval: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Description:      "Health check interval (example: 30s). Equivalent durations (1m and 60s) do not produce a diff. Defaults to the provider `healthcheck_defaults` value or 60s",
+			},
+			resHealthcheckTimeout: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Description:      "Health check timeout (example: 5s). Defaults to the provider `healthcheck_defaults` value or 10s",
 			},
 			resHealthcheckEnabled: {
 				Type:        schema.TypeBool,
@@ -60,14 +89,19 @@ func resourceHealthcheckDNS() *schema.Resource {
 				Description: "Enable the health check on the Appclacks platform",
 			},
 			resHealthcheckDNSDomain: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Domain to check",
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateDomain,
+				Description:      "Domain to check",
 			},
 			resHealthcheckDNSExpectedIPs: {
-				Type:        schema.TypeSet,
-				Optional:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
+				Type:     schema.TypeSet,
+				Optional: true,
+				MaxItems: maxHealthcheckDNSExpectedIPs,
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateIPAddress,
+				},
 				Description: "Expected IP addresses in the answer",
 			},
 		},
@@ -77,6 +111,13 @@ func resourceHealthcheckDNS() *schema.Resource {
 		UpdateContext: resourceHealthcheckDNSUpdate,
 		DeleteContext: resourceHealthcheckDNSDelete,
 
+		CustomizeDiff: customdiff.Sequence(
+			resourceHealthcheckLabelsCustomizeDiff,
+			resourceHealthcheckDefaultsCustomizeDiff("dns"),
+			resourceHealthcheckDurationsCustomizeDiff,
+			resourceHealthcheckTimeoutsCustomizeDiff,
+		),
+
 		Importer: &schema.ResourceImporter{},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -89,59 +130,50 @@ func resourceHealthcheckDNS() *schema.Resource {
 }
 
 func resourceHealthcheckDNSUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutUpdate))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
-	update := goclient.UpdateDNSHealthcheckInput{
-		ID:       d.Id(),
-		Name:     d.Get(resHealthcheckName).(string),
-		Interval: d.Get(resHealthcheckInterval).(string),
-		Timeout:  d.Get(resHealthcheckTimeout).(string),
-		Enabled:  d.Get(resHealthcheckEnabled).(bool),
-		HealthcheckDNSDefinition: goclient.HealthcheckDNSDefinition{
-			Domain: d.Get(resHealthcheckDNSDomain).(string),
-		},
-	}
-
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		update.Description = v.(string)
-	}
-	if l, ok := d.GetOk(resHealthcheckLabels); ok {
-		labels := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			labels[k] = v.(string)
-		}
-		update.Labels = labels
-	}
-
-	if set, ok := d.Get(resHealthcheckDNSExpectedIPs).(*schema.Set); ok {
-		if l := set.Len(); l > 0 {
-			list := make([]string, l)
-			for i, v := range set.List() {
-				list[i] = v.(string)
-			}
-			update.HealthcheckDNSDefinition.ExpectedIPs = list
-
-		}
+	update, err := expandHealthcheckDNSUpdateInput(ctx, d, meta)
+	if err != nil {
+		return client.diagnostics(err)
 	}
 
 	if _, err := client.UpdateDNSHealthcheck(ctx, update); err != nil {
-		return diag.FromErr(err)
+		return client.diagnostics(err)
 	}
 
 	return resourceHealthcheckDNSRead(ctx, d, meta)
 }
 
+// expandHealthcheckDNSUpdateInput builds the payload updating the health check.
+// All optional fields are sent, so the fields removed from the configuration are cleared.
+func expandHealthcheckDNSUpdateInput(ctx context.Context, d *schema.ResourceData, meta interface{}) (goclient.UpdateDNSHealthcheckInput, error) {
+	labels, err := expandHealthcheckUpdateLabels(ctx, d, meta)
+	if err != nil {
+		return goclient.UpdateDNSHealthcheckInput{}, err
+	}
+	return goclient.UpdateDNSHealthcheckInput{
+		ID:                       d.Id(),
+		Name:                     d.Get(resHealthcheckName).(string),
+		Description:              d.Get(resHealthcheckDescription).(string),
+		Labels:                   labels,
+		Interval:                 d.Get(resHealthcheckInterval).(string),
+		Timeout:                  d.Get(resHealthcheckTimeout).(string),
+		Enabled:                  d.Get(resHealthcheckEnabled).(bool),
+		HealthcheckDNSDefinition: expandHealthcheckDNSDefinition(d),
+	}, nil
+}
+
 func resourceHealthcheckDNSDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
 	healthcheckID := d.Id()
 	_, err := client.DeleteHealthcheck(ctx, goclient.DeleteHealthcheckInput{ID: healthcheckID})
-	if err != nil {
-		return diag.FromErr(err)
+	if err != nil && !errors.Is(err, goclient.ErrNotFound) {
+		return client.diagnostics(err)
 	}
 
 	return nil
@@ -153,49 +185,38 @@ func resourceHealthcheckDNSCreate(ctx context.Context, d *schema.ResourceData, m
 
 	client := GetAppclacksClient(meta)
 
-	healthcheck := goclient.CreateDNSHealthcheckInput{
-		Name:     d.Get(resHealthcheckName).(string),
-		Interval: d.Get(resHealthcheckInterval).(string),
-		Timeout:  d.Get(resHealthcheckTimeout).(string),
-		Enabled:  d.Get(resHealthcheckEnabled).(bool),
-		HealthcheckDNSDefinition: goclient.HealthcheckDNSDefinition{
-			Domain: d.Get(resHealthcheckDNSDomain).(string),
-		},
-	}
-
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		healthcheck.Description = v.(string)
-	}
-	if l, ok := d.GetOk(resHealthcheckLabels); ok {
-		labels := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			labels[k] = v.(string)
-		}
-		healthcheck.Labels = labels
+	result, err := client.CreateDNSHealthcheck(ctx, expandHealthcheckDNSCreateInput(d, meta))
+	if err != nil {
+		return client.diagnostics(err)
 	}
 
-	if set, ok := d.Get(resHealthcheckDNSExpectedIPs).(*schema.Set); ok {
-		if l := set.Len(); l > 0 {
-			list := make([]string, l)
-			for i, v := range set.List() {
-				list[i] = v.(string)
-			}
-			healthcheck.ExpectedIPs = list
+	d.SetId(result.ID)
+	return resourceHealthcheckDNSRead(ctx, d, meta)
+}
 
-		}
+// expandHealthcheckDNSCreateInput builds the payload creating the health check
+func expandHealthcheckDNSCreateInput(d *schema.ResourceData, meta interface{}) goclient.CreateDNSHealthcheckInput {
+	return goclient.CreateDNSHealthcheckInput{
+		Name:                     d.Get(resHealthcheckName).(string),
+		Description:              d.Get(resHealthcheckDescription).(string),
+		Labels:                   expandHealthcheckLabels(d, meta),
+		Interval:                 d.Get(resHealthcheckInterval).(string),
+		Timeout:                  d.Get(resHealthcheckTimeout).(string),
+		Enabled:                  d.Get(resHealthcheckEnabled).(bool),
+		HealthcheckDNSDefinition: expandHealthcheckDNSDefinition(d),
 	}
+}
 
-	result, err := client.CreateDNSHealthcheck(ctx, healthcheck)
-	if err != nil {
-		return diag.FromErr(err)
+// expandHealthcheckDNSDefinition builds the DNS definition shared by the create and update payloads
+func expandHealthcheckDNSDefinition(d *schema.ResourceData) goclient.HealthcheckDNSDefinition {
+	return goclient.HealthcheckDNSDefinition{
+		Domain:      d.Get(resHealthcheckDNSDomain).(string),
+		ExpectedIPs: expandStringSet(d.Get(resHealthcheckDNSExpectedIPs)),
 	}
-
-	d.SetId(result.ID)
-	return resourceHealthcheckDNSRead(ctx, d, meta)
 }
 
 func resourceHealthcheckDNSRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutRead))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
@@ -208,12 +229,12 @@ func resourceHealthcheckDNSRead(ctx context.Context, d *schema.ResourceData, met
 			d.SetId("")
 			return nil
 		}
-		return diag.FromErr(err)
+		return client.diagnostics(err)
 	}
-	return diag.FromErr(resourceDNSHealthcheckApply(ctx, d, &result))
+	return diag.FromErr(resourceDNSHealthcheckApply(ctx, d, meta, &result))
 }
 
-func resourceDNSHealthcheckApply(_ context.Context, d *schema.ResourceData, healthcheck *goclient.Healthcheck) error {
+func resourceDNSHealthcheckApply(_ context.Context, d *schema.ResourceData, meta interface{}, healthcheck *goclient.Healthcheck) error {
 
 	if healthcheck.Type != "dns" {
 		return fmt.Errorf("Invalid healthcheck type. Expecting dns, got %s", healthcheck.Type)
@@ -223,21 +244,23 @@ func resourceDNSHealthcheckApply(_ context.Context, d *schema.ResourceData, heal
 		return err
 	}
 
-	if healthcheck.Description != "" {
-		if err := d.Set(resHealthcheckDescription, healthcheck.Description); err != nil {
-			return err
-		}
+	if err := setHealthcheckMetadata(d, healthcheck); err != nil {
+		return err
 	}
 
-	if err := d.Set(resHealthcheckLabels, healthcheck.Labels); err != nil {
+	if err := d.Set(resHealthcheckDescription, healthcheck.Description); err != nil {
 		return err
 	}
 
-	if err := d.Set(resHealthcheckInterval, healthcheck.Interval); err != nil {
+	if err := setHealthcheckLabels(d, meta, healthcheck.Labels); err != nil {
 		return err
 	}
 
-	if err := d.Set(resHealthcheckTimeout, healthcheck.Timeout); err != nil {
+	if err := setDuration(d, resHealthcheckInterval, healthcheck.Interval); err != nil {
+		return err
+	}
+
+	if err := setDuration(d, resHealthcheckTimeout, healthcheck.Timeout); err != nil {
 		return err
 	}
 
@@ -254,10 +277,8 @@ func resourceDNSHealthcheckApply(_ context.Context, d *schema.ResourceData, heal
 		return err
 	}
 
-	if len(definition.ExpectedIPs) != 0 {
-		if err := d.Set(resHealthcheckDNSExpectedIPs, definition.ExpectedIPs); err != nil {
-			return err
-		}
+	if err := d.Set(resHealthcheckDNSExpectedIPs, definition.ExpectedIPs); err != nil {
+		return err
 	}
 
 	return nil
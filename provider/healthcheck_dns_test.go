@@ -3,11 +3,13 @@ package provider
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
 	goclient "github.com/appclacks/go-client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -38,6 +40,13 @@ resource "appclacks_healthcheck_dns" "check_dns" {
   domain = "google.fr"
   expected_ips = ["10.0.0.2"]
 }
+`
+
+	testAccResourceHealthcheckDNSConfigRemoveOptional = `
+resource "appclacks_healthcheck_dns" "check_dns" {
+  name = "tf_acc_dns2"
+  domain = "google.fr"
+}
 `
 )
 
@@ -62,6 +71,9 @@ func TestAccResourceHealthcheckDNS(t *testing.T) {
 						"timeout":        validateString("7s"),
 						"domain":         validateString("google.com"),
 					}),
+					resource.TestCheckResourceAttr("appclacks_healthcheck_dns.check_dns", "type", "dns"),
+					resource.TestCheckResourceAttrPair("appclacks_healthcheck_dns.check_dns", "healthcheck_id", "appclacks_healthcheck_dns.check_dns", "id"),
+					resource.TestCheckResourceAttrSet("appclacks_healthcheck_dns.check_dns", "created_at"),
 				),
 			},
 			{
@@ -100,6 +112,18 @@ func TestAccResourceHealthcheckDNS(t *testing.T) {
 						s[0].Attributes)
 				},
 			},
+			{
+				Config: testAccResourceHealthcheckDNSConfigRemoveOptional,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceCheckExists("appclacks_healthcheck_dns.check_dns", check),
+					testAccCheckResourceCheck(check),
+					testAccCheckResourceDNSCheckAttributes(testAttrs{
+						"description": validateString(""),
+					}),
+					resource.TestCheckNoResourceAttr("appclacks_healthcheck_dns.check_dns", "labels.%"),
+					resource.TestCheckNoResourceAttr("appclacks_healthcheck_dns.check_dns", "expected_ips.#"),
+				),
+			},
 		},
 	})
 }
@@ -141,3 +165,142 @@ func testAccCheckResourceDNSCheckDestroy(s *terraform.State) error {
 
 	return errors.New("DNS Health check still exists")
 }
+
+func TestExpandHealthcheckDNSCreateInput(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected goclient.CreateDNSHealthcheckInput
+	}{
+		{
+			name: "all attributes",
+			raw: map[string]interface{}{
+				resHealthcheckName:           "tf_acc_dns",
+				resHealthcheckDescription:    "foo",
+				resHealthcheckLabels:         map[string]interface{}{"env": "prod"},
+				resHealthcheckInterval:       "30s",
+				resHealthcheckTimeout:        "5s",
+				resHealthcheckEnabled:        false,
+				resHealthcheckDNSDomain:      "appclacks.com",
+				resHealthcheckDNSExpectedIPs: []interface{}{"10.0.0.1"},
+			},
+			expected: goclient.CreateDNSHealthcheckInput{
+				Name:        "tf_acc_dns",
+				Description: "foo",
+				Labels:      map[string]string{"env": "prod", "team": "sre"},
+				Interval:    "30s",
+				Timeout:     "5s",
+				Enabled:     false,
+				HealthcheckDNSDefinition: goclient.HealthcheckDNSDefinition{
+					Domain:      "appclacks.com",
+					ExpectedIPs: []string{"10.0.0.1"},
+				},
+			},
+		},
+		{
+			name: "required attributes",
+			raw: map[string]interface{}{
+				resHealthcheckName:      "tf_acc_dns",
+				resHealthcheckInterval:  "60s",
+				resHealthcheckTimeout:   "10s",
+				resHealthcheckDNSDomain: "appclacks.com",
+			},
+			expected: goclient.CreateDNSHealthcheckInput{
+				Name:     "tf_acc_dns",
+				Labels:   map[string]string{"team": "sre"},
+				Interval: "60s",
+				Timeout:  "10s",
+				Enabled:  true,
+				HealthcheckDNSDefinition: goclient.HealthcheckDNSDefinition{
+					Domain: "appclacks.com",
+				},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceHealthcheckDNS().Schema, c.raw)
+			input := expandHealthcheckDNSCreateInput(d, testExpandMeta())
+			if !reflect.DeepEqual(input, c.expected) {
+				t.Fatalf("expected %+v, got %+v", c.expected, input)
+			}
+		})
+	}
+}
+
+func TestExpandHealthcheckDNSUpdateInput(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceHealthcheckDNS().Schema, map[string]interface{}{
+		resHealthcheckName:           "tf_acc_dns",
+		resHealthcheckLabels:         map[string]interface{}{"env": "prod"},
+		resHealthcheckInterval:       "60s",
+		resHealthcheckTimeout:        "10s",
+		resHealthcheckDNSDomain:      "appclacks.com",
+		resHealthcheckDNSExpectedIPs: []interface{}{"10.0.0.1"},
+	})
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	input, err := expandHealthcheckDNSUpdateInput(context.Background(), d, &providerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := goclient.UpdateDNSHealthcheckInput{
+		ID:       "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:     "tf_acc_dns",
+		Labels:   map[string]string{"env": "prod"},
+		Interval: "60s",
+		Timeout:  "10s",
+		Enabled:  true,
+		HealthcheckDNSDefinition: goclient.HealthcheckDNSDefinition{
+			Domain:      "appclacks.com",
+			ExpectedIPs: []string{"10.0.0.1"},
+		},
+	}
+	if !reflect.DeepEqual(input, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, input)
+	}
+}
+
+func TestResourceDNSHealthcheckApply(t *testing.T) {
+	r := resourceHealthcheckDNS()
+	d := r.TestResourceData()
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	err := resourceDNSHealthcheckApply(context.Background(), d, testExpandMeta(), &goclient.Healthcheck{
+		ID:          "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:        "tf_acc_dns",
+		Description: "foo",
+		Labels:      map[string]string{"env": "prod", "team": "sre"},
+		Type:        "dns",
+		Interval:    "30s",
+		Timeout:     "5s",
+		Enabled:     false,
+		CreatedAt:   time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+		Definition: goclient.HealthcheckDNSDefinition{
+			Domain:      "appclacks.com",
+			ExpectedIPs: []string{"10.0.0.2", "10.0.0.1"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCheckResourceDataValues(t, r, d, map[string]interface{}{
+		resHealthcheckName:           "tf_acc_dns",
+		resHealthcheckDescription:    "foo",
+		resHealthcheckLabels:         map[string]interface{}{"env": "prod"},
+		resHealthcheckLabelsAll:      map[string]interface{}{"env": "prod", "team": "sre"},
+		resHealthcheckID:             "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		resHealthcheckType:           "dns",
+		resHealthcheckCreatedAt:      "2023-01-02T15:04:05Z",
+		resHealthcheckInterval:       "30s",
+		resHealthcheckTimeout:        "5s",
+		resHealthcheckEnabled:        false,
+		resHealthcheckDNSDomain:      "appclacks.com",
+		resHealthcheckDNSExpectedIPs: []interface{}{"10.0.0.1", "10.0.0.2"},
+	})
+
+	err = resourceDNSHealthcheckApply(context.Background(), d, testExpandMeta(), &goclient.Healthcheck{
+		Type:       "tcp",
+		Definition: goclient.HealthcheckTCPDefinition{},
+	})
+	if err == nil || err.Error() != "Invalid healthcheck type. Expecting dns, got tcp" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testHealthcheckPayloads returns the API payloads stored in testdata/healthchecks, by file name
+func testHealthcheckPayloads(t testing.TB) map[string][]byte {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join("testdata", "healthchecks", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no health check payload found in testdata/healthchecks")
+	}
+	payloads := make(map[string][]byte, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payloads[strings.TrimSuffix(filepath.Base(file), ".json")] = content
+	}
+	return payloads
+}
+
+// testHealthcheckTypes associates each health check type to its definition, resource and apply function
+var testHealthcheckTypes = map[string]struct {
+	definition reflect.Type
+	resource   func() *schema.Resource
+	apply      func(context.Context, *schema.ResourceData, interface{}, *goclient.Healthcheck) error
+}{
+	"command": {reflect.TypeOf(goclient.HealthcheckCommandDefinition{}), resourceHealthcheckCommand, resourceCommandHealthcheckApply},
+	"dns":     {reflect.TypeOf(goclient.HealthcheckDNSDefinition{}), resourceHealthcheckDNS, resourceDNSHealthcheckApply},
+	"http":    {reflect.TypeOf(goclient.HealthcheckHTTPDefinition{}), resourceHealthcheckHTTP, resourceHTTPHealthcheckApply},
+	"tcp":     {reflect.TypeOf(goclient.HealthcheckTCPDefinition{}), resourceHealthcheckTCP, resourceTCPHealthcheckApply},
+	"tls":     {reflect.TypeOf(goclient.HealthcheckTLSDefinition{}), resourceHealthcheckTLS, resourceTLSHealthcheckApply},
+}
+
+func TestHealthcheckPayloads(t *testing.T) {
+	for name, payload := range testHealthcheckPayloads(t) {
+		t.Run(name, func(t *testing.T) {
+			var healthcheck goclient.Healthcheck
+			err := json.Unmarshal(payload, &healthcheck)
+			if name == "unknown" {
+				if err == nil || !strings.Contains(err.Error(), "Unknown healthcheck type") {
+					t.Fatalf("expected an unknown type error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if healthcheck.Type != name {
+				t.Fatalf("expected type %s, got %s", name, healthcheck.Type)
+			}
+			healthcheckType := testHealthcheckTypes[name]
+			if reflect.TypeOf(healthcheck.Definition) != healthcheckType.definition {
+				t.Fatalf("expected a %s definition, got %T", healthcheckType.definition, healthcheck.Definition)
+			}
+			d := healthcheckType.resource().TestResourceData()
+			if err := healthcheckType.apply(context.Background(), d, &providerConfig{}, &healthcheck); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func FuzzHealthcheckUnmarshal(f *testing.F) {
+	for _, payload := range testHealthcheckPayloads(f) {
+		f.Add(payload)
+	}
+	f.Add([]byte(`{}`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(`{"type":"http","port":-1}`))
+	f.Add([]byte(`{"type":"dns","expected-ips":"10.0.0.1"}`))
+	f.Add([]byte(`{"type":"tcp","created-at":"yesterday"}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var healthcheck goclient.Healthcheck
+		err := json.Unmarshal(data, &healthcheck)
+
+		var payload struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(data, &payload) == nil {
+			if _, ok := testHealthcheckTypes[payload.Type]; !ok && err == nil {
+				t.Fatalf("expected an error for the unknown type %q", payload.Type)
+			}
+		}
+		if err != nil {
+			return
+		}
+
+		healthcheckType, ok := testHealthcheckTypes[healthcheck.Type]
+		if !ok {
+			t.Fatalf("unknown type %q decoded without error", healthcheck.Type)
+		}
+		if reflect.TypeOf(healthcheck.Definition) != healthcheckType.definition {
+			t.Fatalf("expected a %s definition for the type %s, got %T", healthcheckType.definition, healthcheck.Type, healthcheck.Definition)
+		}
+		// the decoded health check is stored in the state on every Read, errors are
+		// allowed but it should never panic
+		d := healthcheckType.resource().TestResourceData()
+		_ = healthcheckType.apply(context.Background(), d, &providerConfig{}, &healthcheck)
+	})
+}
+
+// testSchemaValid reports whether the validator of an integer attribute accepts the value
+func testSchemaValid(s *schema.Schema, value uint) bool {
+	if value > math.MaxInt {
+		return false
+	}
+	return !s.ValidateDiagFunc(int(value), cty.Path{}).HasError()
+}
+
+func FuzzHealthcheckRoundTrip(f *testing.F) {
+	f.Add("tf_acc", "description", "env", "prod", true, "appclacks.com", uint(443), "/healthz", "GET", "ok", false, uint(200))
+	f.Add("", "", "", "", false, "", uint(0), "", "", "", true, uint(0))
+	f.Add("<check>", "\"quoted\" & escaped\n", "a=b", "ü", true, "10.0.0.1", uint(65535), "168h", "-----BEGIN CERTIFICATE-----\n", "^pong$", true, uint(999))
+	f.Add("tf_acc", "", "", "", true, "appclacks.com", uint(65536), "", "", "", false, uint(1000))
+	f.Add("tf_acc", "", "", "", true, "appclacks.com", uint(1<<53+1), "", "", "", false, uint(1<<63))
+
+	f.Fuzz(func(t *testing.T, name, description, labelKey, labelValue string, enabled bool, target string, port uint, s1, s2, s3 string, flag bool, status uint) {
+		for _, s := range []string{name, description, labelKey, labelValue, target, s1, s2, s3} {
+			// invalid UTF-8 is replaced by the JSON encoder
+			if !utf8.ValidString(s) {
+				t.Skip()
+			}
+		}
+		definitions := map[string]any{
+			"command": goclient.HealthcheckCommandDefinition{
+				Command:   target,
+				Arguments: []string{s1, s2, s3},
+			},
+			"dns": goclient.HealthcheckDNSDefinition{
+				Domain:      target,
+				ExpectedIPs: []string{s1},
+			},
+			"http": goclient.HealthcheckHTTPDefinition{
+				ValidStatus: []uint{status},
+				Target:      target,
+				Method:      s2,
+				Port:        port,
+				Host:        s3,
+				Redirect:    flag,
+				Query:       map[string]string{labelKey: s1},
+				Body:        s3,
+				BodyRegexp:  []string{s3},
+				Headers:     map[string]string{labelValue: s2},
+				Protocol:    s2,
+				Path:        s1,
+				Key:         s2,
+				Cert:        s3,
+				Cacert:      s1,
+				Insecure:    !flag,
+				ServerName:  s2,
+			},
+			"tcp": goclient.HealthcheckTCPDefinition{
+				Target:     target,
+				Port:       port,
+				ShouldFail: flag,
+			},
+			"tls": goclient.HealthcheckTLSDefinition{
+				Target:          target,
+				Port:            port,
+				Key:             s1,
+				Cert:            s2,
+				Cacert:          s3,
+				ServerName:      s1,
+				Insecure:        flag,
+				ExpirationDelay: s2,
+			},
+		}
+		// the definition is merged into the payload through a map[string]any, so the
+		// numbers are encoded as float64: the values rejected by the schema validators
+		// are not sent to the API, all the others should round-trip exactly
+		for healthcheckType := range definitions {
+			r := testHealthcheckTypes[healthcheckType].resource()
+			if _, ok := r.Schema[resHealthcheckPort]; ok && !testSchemaValid(r.Schema[resHealthcheckPort], port) {
+				delete(definitions, healthcheckType)
+			}
+			if _, ok := r.Schema[resHealthcheckHTTPValidStatus]; ok && !testSchemaValid(r.Schema[resHealthcheckHTTPValidStatus].Elem.(*schema.Schema), status) {
+				delete(definitions, healthcheckType)
+			}
+		}
+		for healthcheckType, definition := range definitions {
+			healthcheck := goclient.Healthcheck{
+				ID:          "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+				Name:        name,
+				Description: description,
+				Type:        healthcheckType,
+				Labels:      map[string]string{labelKey: labelValue},
+				Timeout:     s1,
+				Interval:    s2,
+				CreatedAt:   time.Date(2023, 1, 2, 15, 4, 5, 123456789, time.UTC),
+				Enabled:     enabled,
+				Definition:  definition,
+			}
+			data, err := json.Marshal(&healthcheck)
+			if err != nil {
+				t.Fatalf("failed to encode the %s health check: %s", healthcheckType, err)
+			}
+			var result goclient.Healthcheck
+			if err := json.Unmarshal(data, &result); err != nil {
+				t.Fatalf("failed to decode the %s health check %s: %s", healthcheckType, data, err)
+			}
+			if !reflect.DeepEqual(result, healthcheck) {
+				t.Fatalf("the %s health check changed after a round-trip\nexpected: %+v\ngot:      %+v\npayload: %s", healthcheckType, healthcheck, result, data)
+			}
+		}
+	})
+}
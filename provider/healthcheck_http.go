@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	goclient "github.com/appclacks/go-client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const (
@@ -21,11 +24,19 @@ const (
 	resHealthcheckHTTPProtocol    = "protocol"
 	resHealthcheckHTTPPath        = "path"
 	resHealthcheckHTTPHost        = "host"
+
+	maxHealthcheckHTTPValidStatus = 20
+	maxHealthcheckHTTPStatus      = 1000
+	maxHealthcheckHTTPBodyRegexp  = 3
+	maxHealthcheckHTTPHeaders     = 20
+	maxHealthcheckHTTPQuery       = 20
 )
 
+var healthcheckHTTPMethods = []string{"GET", "POST", "PUT", "DELETE", "HEAD"}
+
 func resourceHealthcheckHTTP() *schema.Resource {
 	return &schema.Resource{
-		Description: "Execute an HTTP request on the target",
+		Description: "Execute an HTTP request on the target. The TLS settings ignored by the prober are reported as warnings by terraform plan and apply",
 		Schema: map[string]*schema.Schema{
 			resHealthcheckName: {
 				Type:        schema.TypeString,
@@ -38,22 +49,48 @@ func resourceHealthcheckHTTP() *schema.Resource {
 				Description: "Health check description",
 			},
 			resHealthcheckLabels: {
+				Type:             schema.TypeMap,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				Optional:         true,
+				ValidateDiagFunc: validateLabels,
+				Description:      "Health check labels. Keys and values must be between 1 and 255 characters",
+			},
+			resHealthcheckLabelsAll: {
 				Type:        schema.TypeMap,
 				Elem:        &schema.Schema{Type: schema.TypeString},
-				Optional:    true,
-				Description: "Health check labels",
+				Computed:    true,
+				Description: "All labels of the health check, including the provider default labels",
 			},
-			resHealthcheckInterval: {
+			resHealthcheckID: {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     defaultHealthcheckInterval,
-				Description: "Health check interval (example: 30s)",
+				Computed:    true,
+				Description: "Health check ID",
 			},
-			resHealthcheckTimeout: {
+			resHealthcheckType: {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     defaultHealthcheckTimeout,
-				Description: "Health check timeout (example: 5s)",
+				Computed:    true,
+				Description: "Health check type",
+			},
+			resHealthcheckCreatedAt: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Health check creation date (RFC 3339)",
+			},
+			resHealthcheckInterval: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Description:      "Health check interval (example: 30s). Equivalent durations (1m and 60s) do not produce a diff. Defaults to the provider `healthcheck_defaults` value or 60s",
+			},
+			resHealthcheckTimeout: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Description:      "Health check timeout (example: 5s). Defaults to the provider `healthcheck_defaults` value or 10s",
 			},
 			resHealthcheckEnabled: {
 				Type:        schema.TypeBool,
@@ -62,32 +99,42 @@ func resourceHealthcheckHTTP() *schema.Resource {
 				Description: "Enable the health check on the Appclacks platform",
 			},
 			resHealthcheckTarget: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Health check target (can be a domain or an IP address)",
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateHostnameOrIP,
+				Description:      "Health check target (can be a domain or an IP address)",
 			},
 			resHealthcheckHTTPValidStatus: {
-				Type:        schema.TypeSet,
-				Required:    true,
-				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				MaxItems: maxHealthcheckHTTPValidStatus,
+				Elem: &schema.Schema{
+					Type:             schema.TypeInt,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.IntBetween(1, maxHealthcheckHTTPStatus)),
+				},
 				Description: "Expected status code(s) for the HTTP response",
 			},
 			resHealthcheckPort: {
-				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "Health check port",
+				Type:             schema.TypeInt,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsPortNumber),
+				Description:      "Health check port",
 			},
 			resHealthcheckHTTPMethod: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     "GET",
-				Description: "Health check HTTP method",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(healthcheckHTTPMethods, true)),
+				DiffSuppressFunc: suppressCaseInsensitive,
+				Description:      "Health check HTTP method (case insensitive). Defaults to the provider `healthcheck_defaults` value or GET",
 			},
 			resHealthcheckHTTPProtocol: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     "https",
-				Description: "Health check protocol to use (http or https)",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"http", "https"}, false)),
+				Default:          "https",
+				Description:      "Health check protocol to use (http or https)",
 			},
 			resHealthcheckHTTPPath: {
 				Type:        schema.TypeString,
@@ -106,32 +153,39 @@ func resourceHealthcheckHTTP() *schema.Resource {
 				Description: "Health check request HTTP body",
 			},
 			resHealthcheckHTTPHeaders: {
-				Type:        schema.TypeMap,
-				Optional:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
-				Description: "Health check request HTTP headers",
+				Type:             schema.TypeMap,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateHTTPHeaders(maxHealthcheckHTTPHeaders),
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				Description:      "Health check request HTTP headers, merged with the provider `healthcheck_defaults` headers. Header names are stored in their canonical form (example: Content-Type)",
 			},
 			resHealthcheckHTTPQuery: {
-				Type:        schema.TypeMap,
-				Optional:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
-				Description: "Health check request HTTP query parameters",
+				Type:             schema.TypeMap,
+				Optional:         true,
+				ValidateDiagFunc: validateMapMaxItems(maxHealthcheckHTTPQuery),
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				Description:      "Health check request HTTP query parameters",
 			},
 			resHealthcheckHTTPBodyRegexp: {
-				Type:        schema.TypeSet,
-				Optional:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
+				Type:     schema.TypeSet,
+				Optional: true,
+				MaxItems: maxHealthcheckHTTPBodyRegexp,
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+				},
 				Description: "A list of regular expression which will be executed against the response body",
 			},
 			resHealthcheckTLSKey: {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "TLS key file to use for the TLS connection",
+				Description: "TLS key file to use for the TLS connection. Must be set together with cert",
 			},
 			resHealthcheckTLSCert: {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "TLS cert file to use for the TLS connection",
+				Description: "TLS cert file to use for the TLS connection. Must be set together with key",
 			},
 			resHealthcheckTLSCacert: {
 				Type:        schema.TypeString,
@@ -146,12 +200,12 @@ func resourceHealthcheckHTTP() *schema.Resource {
 			resHealthcheckTLSServerName: {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "Server name to use for the TLS connection",
+				Description: "Server name to use for the TLS connection. Ignored when the protocol is http",
 			},
 			resHealthcheckTLSInsecure: {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "Accept insecure TLS connections",
+				Description: "Accept insecure TLS connections. Ignored when the protocol is http",
 			},
 		},
 
@@ -160,6 +214,14 @@ func resourceHealthcheckHTTP() *schema.Resource {
 		UpdateContext: resourceHealthcheckHTTPUpdate,
 		DeleteContext: resourceHealthcheckHTTPDelete,
 
+		CustomizeDiff: customdiff.Sequence(
+			resourceHealthcheckLabelsCustomizeDiff,
+			resourceHealthcheckDefaultsCustomizeDiff("http"),
+			resourceHealthcheckDurationsCustomizeDiff,
+			resourceHealthcheckTimeoutsCustomizeDiff,
+			resourceHealthcheckTLSCustomizeDiff,
+		),
+
 		Importer: &schema.ResourceImporter{},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -172,114 +234,50 @@ func resourceHealthcheckHTTP() *schema.Resource {
 }
 
 func resourceHealthcheckHTTPUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutUpdate))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
-	update := goclient.UpdateHTTPHealthcheckInput{
-		ID:       d.Id(),
-		Name:     d.Get(resHealthcheckName).(string),
-		Interval: d.Get(resHealthcheckInterval).(string),
-		Timeout:  d.Get(resHealthcheckTimeout).(string),
-		Enabled:  d.Get(resHealthcheckEnabled).(bool),
-		HealthcheckHTTPDefinition: goclient.HealthcheckHTTPDefinition{
-			Target:   d.Get(resHealthcheckTarget).(string),
-			Port:     uint(d.Get(resHealthcheckPort).(int)),
-			Method:   d.Get(resHealthcheckHTTPMethod).(string),
-			Protocol: d.Get(resHealthcheckHTTPProtocol).(string),
-		},
-	}
-
-	if l, ok := d.GetOk(resHealthcheckLabels); ok {
-		labels := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			labels[k] = v.(string)
-		}
-		update.Labels = labels
-	}
-
-	if set, ok := d.Get(resHealthcheckHTTPValidStatus).(*schema.Set); ok {
-		if l := set.Len(); l > 0 {
-			list := make([]uint, l)
-			for i, v := range set.List() {
-				list[i] = uint(v.(int))
-			}
-			update.HealthcheckHTTPDefinition.ValidStatus = list
-
-		}
-	}
-
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		update.Description = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckHTTPPath); ok {
-		update.HealthcheckHTTPDefinition.Path = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckHTTPRedirect); ok {
-		update.HealthcheckHTTPDefinition.Redirect = v.(bool)
-	}
-	if v, ok := d.GetOk(resHealthcheckHTTPBody); ok {
-		update.HealthcheckHTTPDefinition.Body = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSCert); ok {
-		update.HealthcheckHTTPDefinition.Cert = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSCacert); ok {
-		update.HealthcheckHTTPDefinition.Cacert = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSKey); ok {
-		update.HealthcheckHTTPDefinition.Key = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckHTTPHost); ok {
-		update.HealthcheckHTTPDefinition.Host = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSInsecure); ok {
-		update.HealthcheckHTTPDefinition.Insecure = v.(bool)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSServerName); ok {
-		update.HealthcheckHTTPDefinition.ServerName = v.(string)
-	}
-	if set, ok := d.Get(resHealthcheckHTTPBodyRegexp).(*schema.Set); ok {
-		if l := set.Len(); l > 0 {
-			list := make([]string, l)
-			for i, v := range set.List() {
-				list[i] = v.(string)
-			}
-			update.HealthcheckHTTPDefinition.BodyRegexp = list
-
-		}
-	}
-	if l, ok := d.GetOk(resHealthcheckHTTPHeaders); ok {
-		headers := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			headers[k] = v.(string)
-		}
-		update.HealthcheckHTTPDefinition.Headers = headers
-	}
-	if l, ok := d.GetOk(resHealthcheckHTTPQuery); ok {
-		query := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			query[k] = v.(string)
-		}
-		update.HealthcheckHTTPDefinition.Query = query
+	update, err := expandHealthcheckHTTPUpdateInput(ctx, d, meta)
+	if err != nil {
+		return client.diagnostics(err)
 	}
 
 	if _, err := client.UpdateHTTPHealthcheck(ctx, update); err != nil {
-		return diag.FromErr(err)
+		return client.diagnostics(err)
 	}
 
 	return resourceHealthcheckHTTPRead(ctx, d, meta)
 }
 
+// expandHealthcheckHTTPUpdateInput builds the payload updating the health check.
+// All optional fields are sent, so the fields removed from the configuration are cleared.
+func expandHealthcheckHTTPUpdateInput(ctx context.Context, d *schema.ResourceData, meta interface{}) (goclient.UpdateHTTPHealthcheckInput, error) {
+	labels, err := expandHealthcheckUpdateLabels(ctx, d, meta)
+	if err != nil {
+		return goclient.UpdateHTTPHealthcheckInput{}, err
+	}
+	return goclient.UpdateHTTPHealthcheckInput{
+		ID:                        d.Id(),
+		Name:                      d.Get(resHealthcheckName).(string),
+		Description:               d.Get(resHealthcheckDescription).(string),
+		Labels:                    labels,
+		Interval:                  d.Get(resHealthcheckInterval).(string),
+		Timeout:                   d.Get(resHealthcheckTimeout).(string),
+		Enabled:                   d.Get(resHealthcheckEnabled).(bool),
+		HealthcheckHTTPDefinition: expandHealthcheckHTTPDefinition(d),
+	}, nil
+}
+
 func resourceHealthcheckHTTPDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
 	healthcheckID := d.Id()
 	_, err := client.DeleteHealthcheck(ctx, goclient.DeleteHealthcheckInput{ID: healthcheckID})
-	if err != nil {
-		return diag.FromErr(err)
+	if err != nil && !errors.Is(err, goclient.ErrNotFound) {
+		return client.diagnostics(err)
 	}
 
 	return nil
@@ -291,106 +289,53 @@ func resourceHealthcheckHTTPCreate(ctx context.Context, d *schema.ResourceData,
 
 	client := GetAppclacksClient(meta)
 
-	healthcheck := goclient.CreateHTTPHealthcheckInput{
-		Name:     d.Get(resHealthcheckName).(string),
-		Interval: d.Get(resHealthcheckInterval).(string),
-		Timeout:  d.Get(resHealthcheckTimeout).(string),
-		Enabled:  d.Get(resHealthcheckEnabled).(bool),
-		HealthcheckHTTPDefinition: goclient.HealthcheckHTTPDefinition{
-			Target:   d.Get(resHealthcheckTarget).(string),
-			Port:     uint(d.Get(resHealthcheckPort).(int)),
-			Method:   d.Get(resHealthcheckHTTPMethod).(string),
-			Protocol: d.Get(resHealthcheckHTTPProtocol).(string),
-		},
-	}
-
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		healthcheck.Description = v.(string)
-	}
-	if set, ok := d.Get(resHealthcheckHTTPValidStatus).(*schema.Set); ok {
-		if l := set.Len(); l > 0 {
-			list := make([]uint, l)
-			for i, v := range set.List() {
-				list[i] = uint(v.(int))
-			}
-			healthcheck.HealthcheckHTTPDefinition.ValidStatus = list
-
-		}
-	}
-	if l, ok := d.GetOk(resHealthcheckLabels); ok {
-		labels := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			labels[k] = v.(string)
-		}
-		healthcheck.Labels = labels
+	result, err := client.CreateHTTPHealthcheck(ctx, expandHealthcheckHTTPCreateInput(d, meta))
+	if err != nil {
+		return client.diagnostics(err)
 	}
 
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		healthcheck.Description = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckHTTPPath); ok {
-		healthcheck.HealthcheckHTTPDefinition.Path = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckHTTPRedirect); ok {
-		healthcheck.HealthcheckHTTPDefinition.Redirect = v.(bool)
-	}
-	if v, ok := d.GetOk(resHealthcheckHTTPBody); ok {
-		healthcheck.HealthcheckHTTPDefinition.Body = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSCert); ok {
-		healthcheck.HealthcheckHTTPDefinition.Cert = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSCacert); ok {
-		healthcheck.HealthcheckHTTPDefinition.Cacert = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSKey); ok {
-		healthcheck.HealthcheckHTTPDefinition.Key = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckHTTPHost); ok {
-		healthcheck.HealthcheckHTTPDefinition.Host = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSInsecure); ok {
-		healthcheck.HealthcheckHTTPDefinition.Insecure = v.(bool)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSServerName); ok {
-		healthcheck.HealthcheckHTTPDefinition.ServerName = v.(string)
-	}
-	if set, ok := d.Get(resHealthcheckHTTPBodyRegexp).(*schema.Set); ok {
-		if l := set.Len(); l > 0 {
-			list := make([]string, l)
-			for i, v := range set.List() {
-				list[i] = v.(string)
-			}
-			healthcheck.HealthcheckHTTPDefinition.BodyRegexp = list
+	d.SetId(result.ID)
+	return resourceHealthcheckHTTPRead(ctx, d, meta)
+}
 
-		}
-	}
-	if l, ok := d.GetOk(resHealthcheckHTTPHeaders); ok {
-		headers := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			headers[k] = v.(string)
-		}
-		healthcheck.HealthcheckHTTPDefinition.Headers = headers
-	}
-	if l, ok := d.GetOk(resHealthcheckHTTPQuery); ok {
-		query := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			query[k] = v.(string)
-		}
-		healthcheck.HealthcheckHTTPDefinition.Query = query
+// expandHealthcheckHTTPCreateInput builds the payload creating the health check
+func expandHealthcheckHTTPCreateInput(d *schema.ResourceData, meta interface{}) goclient.CreateHTTPHealthcheckInput {
+	return goclient.CreateHTTPHealthcheckInput{
+		Name:                      d.Get(resHealthcheckName).(string),
+		Description:               d.Get(resHealthcheckDescription).(string),
+		Labels:                    expandHealthcheckLabels(d, meta),
+		Interval:                  d.Get(resHealthcheckInterval).(string),
+		Timeout:                   d.Get(resHealthcheckTimeout).(string),
+		Enabled:                   d.Get(resHealthcheckEnabled).(bool),
+		HealthcheckHTTPDefinition: expandHealthcheckHTTPDefinition(d),
 	}
+}
 
-	result, err := client.CreateHTTPHealthcheck(ctx, healthcheck)
-	if err != nil {
-		return diag.FromErr(err)
+// expandHealthcheckHTTPDefinition builds the HTTP definition shared by the create and update payloads
+func expandHealthcheckHTTPDefinition(d *schema.ResourceData) goclient.HealthcheckHTTPDefinition {
+	return goclient.HealthcheckHTTPDefinition{
+		Target:      d.Get(resHealthcheckTarget).(string),
+		Port:        uint(d.Get(resHealthcheckPort).(int)),
+		Method:      strings.ToUpper(d.Get(resHealthcheckHTTPMethod).(string)),
+		Protocol:    d.Get(resHealthcheckHTTPProtocol).(string),
+		ValidStatus: expandUintSet(d.Get(resHealthcheckHTTPValidStatus)),
+		Path:        d.Get(resHealthcheckHTTPPath).(string),
+		Redirect:    d.Get(resHealthcheckHTTPRedirect).(bool),
+		Body:        d.Get(resHealthcheckHTTPBody).(string),
+		BodyRegexp:  expandStringSet(d.Get(resHealthcheckHTTPBodyRegexp)),
+		Headers:     expandStringMap(d.Get(resHealthcheckHTTPHeaders)),
+		Query:       expandStringMap(d.Get(resHealthcheckHTTPQuery)),
+		Host:        d.Get(resHealthcheckHTTPHost).(string),
+		Cert:        d.Get(resHealthcheckTLSCert).(string),
+		Cacert:      d.Get(resHealthcheckTLSCacert).(string),
+		Key:         d.Get(resHealthcheckTLSKey).(string),
+		Insecure:    d.Get(resHealthcheckTLSInsecure).(bool),
+		ServerName:  d.Get(resHealthcheckTLSServerName).(string),
 	}
-
-	d.SetId(result.ID)
-	return resourceHealthcheckHTTPRead(ctx, d, meta)
 }
 
 func resourceHealthcheckHTTPRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutRead))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
@@ -403,36 +348,38 @@ func resourceHealthcheckHTTPRead(ctx context.Context, d *schema.ResourceData, me
 			d.SetId("")
 			return nil
 		}
-		return diag.FromErr(err)
+		return client.diagnostics(err)
 	}
-	return diag.FromErr(resourceHTTPHealthcheckApply(ctx, d, &result))
+	return diag.FromErr(resourceHTTPHealthcheckApply(ctx, d, meta, &result))
 }
 
-func resourceHTTPHealthcheckApply(_ context.Context, d *schema.ResourceData, healthcheck *goclient.Healthcheck) error {
+func resourceHTTPHealthcheckApply(_ context.Context, d *schema.ResourceData, meta interface{}, healthcheck *goclient.Healthcheck) error {
 
 	if healthcheck.Type != "http" {
-		return fmt.Errorf("Invalid healthcheck type. Expecting tcp, got %s", healthcheck.Type)
+		return fmt.Errorf("Invalid healthcheck type. Expecting http, got %s", healthcheck.Type)
 	}
 
 	if err := d.Set(resHealthcheckName, healthcheck.Name); err != nil {
 		return err
 	}
 
-	if healthcheck.Description != "" {
-		if err := d.Set(resHealthcheckDescription, healthcheck.Description); err != nil {
-			return err
-		}
+	if err := setHealthcheckMetadata(d, healthcheck); err != nil {
+		return err
+	}
+
+	if err := d.Set(resHealthcheckDescription, healthcheck.Description); err != nil {
+		return err
 	}
 
-	if err := d.Set(resHealthcheckLabels, healthcheck.Labels); err != nil {
+	if err := setHealthcheckLabels(d, meta, healthcheck.Labels); err != nil {
 		return err
 	}
 
-	if err := d.Set(resHealthcheckInterval, healthcheck.Interval); err != nil {
+	if err := setDuration(d, resHealthcheckInterval, healthcheck.Interval); err != nil {
 		return err
 	}
 
-	if err := d.Set(resHealthcheckTimeout, healthcheck.Timeout); err != nil {
+	if err := setDuration(d, resHealthcheckTimeout, healthcheck.Timeout); err != nil {
 		return err
 	}
 
@@ -474,7 +421,7 @@ func resourceHTTPHealthcheckApply(_ context.Context, d *schema.ResourceData, hea
 	if err := d.Set(resHealthcheckHTTPBodyRegexp, definition.BodyRegexp); err != nil {
 		return err
 	}
-	if err := d.Set(resHealthcheckHTTPHeaders, definition.Headers); err != nil {
+	if err := d.Set(resHealthcheckHTTPHeaders, canonicalHeaders(definition.Headers)); err != nil {
 		return err
 	}
 	if err := d.Set(resHealthcheckHTTPQuery, definition.Query); err != nil {
@@ -3,11 +3,13 @@ package provider
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
 	goclient "github.com/appclacks/go-client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -74,6 +76,15 @@ resource "appclacks_healthcheck_http" "check_http" {
   insecure = false
   server_name = "google.fr"
 }
+`
+
+	testAccResourceHealthcheckHTTPConfigRemoveOptional = `
+resource "appclacks_healthcheck_http" "check_http" {
+  name = "tf_acc_http2"
+  target = "google.fr"
+  port = 80
+  valid_status = [200]
+}
 `
 )
 
@@ -109,6 +120,9 @@ func TestAccResourceHealthcheckHTTP(t *testing.T) {
 						"server_name":    validateString("google.com"),
 						"insecure":       validateString("true"),
 					}),
+					resource.TestCheckResourceAttr("appclacks_healthcheck_http.check_http", "type", "http"),
+					resource.TestCheckResourceAttrPair("appclacks_healthcheck_http.check_http", "healthcheck_id", "appclacks_healthcheck_http.check_http", "id"),
+					resource.TestCheckResourceAttrSet("appclacks_healthcheck_http.check_http", "created_at"),
 				),
 			},
 			{
@@ -168,6 +182,31 @@ func TestAccResourceHealthcheckHTTP(t *testing.T) {
 						s[0].Attributes)
 				},
 			},
+			{
+				Config: testAccResourceHealthcheckHTTPConfigRemoveOptional,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceCheckExists("appclacks_healthcheck_http.check_http", check),
+					testAccCheckResourceCheck(check),
+					testAccCheckResourceHTTPCheckAttributes(testAttrs{
+						"description": validateString(""),
+						"body":        validateString(""),
+						"path":        validateString(""),
+						"cert":        validateString(""),
+						"cacert":      validateString(""),
+						"key":         validateString(""),
+						"host":        validateString(""),
+						"server_name": validateString(""),
+						"insecure":    validateString("false"),
+						"redirect":    validateString("false"),
+						"method":      validateString("GET"),
+						"protocol":    validateString("https"),
+					}),
+					resource.TestCheckNoResourceAttr("appclacks_healthcheck_http.check_http", "labels.%"),
+					resource.TestCheckNoResourceAttr("appclacks_healthcheck_http.check_http", "body_regexp.#"),
+					resource.TestCheckNoResourceAttr("appclacks_healthcheck_http.check_http", "headers.%"),
+					resource.TestCheckNoResourceAttr("appclacks_healthcheck_http.check_http", "query.%"),
+				),
+			},
 		},
 	})
 }
@@ -209,3 +248,274 @@ func testAccCheckResourceHTTPCheckDestroy(s *terraform.State) error {
 
 	return errors.New("HTTP Health check still exists")
 }
+
+func TestExpandHealthcheckHTTPCreateInput(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected goclient.CreateHTTPHealthcheckInput
+	}{
+		{
+			name: "all attributes",
+			raw: map[string]interface{}{
+				resHealthcheckName:            "tf_acc_http",
+				resHealthcheckDescription:     "foo",
+				resHealthcheckLabels:          map[string]interface{}{"env": "prod"},
+				resHealthcheckInterval:        "30s",
+				resHealthcheckTimeout:         "5s",
+				resHealthcheckEnabled:         false,
+				resHealthcheckTarget:          "appclacks.com",
+				resHealthcheckPort:            443,
+				resHealthcheckHTTPValidStatus: []interface{}{200},
+				resHealthcheckHTTPMethod:      "post",
+				resHealthcheckHTTPProtocol:    "https",
+				resHealthcheckHTTPPath:        "/healthz",
+				resHealthcheckHTTPRedirect:    true,
+				resHealthcheckHTTPBody:        "{}",
+				resHealthcheckHTTPBodyRegexp:  []interface{}{"ok"},
+				resHealthcheckHTTPHeaders:     map[string]interface{}{"Content-Type": "application/json"},
+				resHealthcheckHTTPQuery:       map[string]interface{}{"foo": "bar"},
+				resHealthcheckHTTPHost:        "api.appclacks.com",
+				resHealthcheckTLSKey:          "key",
+				resHealthcheckTLSCert:         "cert",
+				resHealthcheckTLSCacert:       "cacert",
+				resHealthcheckTLSServerName:   "api.appclacks.com",
+				resHealthcheckTLSInsecure:     true,
+			},
+			expected: goclient.CreateHTTPHealthcheckInput{
+				Name:        "tf_acc_http",
+				Description: "foo",
+				Labels:      map[string]string{"env": "prod", "team": "sre"},
+				Interval:    "30s",
+				Timeout:     "5s",
+				Enabled:     false,
+				HealthcheckHTTPDefinition: goclient.HealthcheckHTTPDefinition{
+					ValidStatus: []uint{200},
+					Target:      "appclacks.com",
+					Method:      "POST",
+					Port:        443,
+					Host:        "api.appclacks.com",
+					Redirect:    true,
+					Query:       map[string]string{"foo": "bar"},
+					Body:        "{}",
+					BodyRegexp:  []string{"ok"},
+					Headers:     map[string]string{"Content-Type": "application/json"},
+					Protocol:    "https",
+					Path:        "/healthz",
+					Key:         "key",
+					Cert:        "cert",
+					Cacert:      "cacert",
+					Insecure:    true,
+					ServerName:  "api.appclacks.com",
+				},
+			},
+		},
+		{
+			name: "required attributes",
+			raw: map[string]interface{}{
+				resHealthcheckName:            "tf_acc_http",
+				resHealthcheckInterval:        "60s",
+				resHealthcheckTimeout:         "10s",
+				resHealthcheckTarget:          "appclacks.com",
+				resHealthcheckPort:            443,
+				resHealthcheckHTTPValidStatus: []interface{}{200},
+				resHealthcheckHTTPMethod:      "GET",
+			},
+			expected: goclient.CreateHTTPHealthcheckInput{
+				Name:     "tf_acc_http",
+				Labels:   map[string]string{"team": "sre"},
+				Interval: "60s",
+				Timeout:  "10s",
+				Enabled:  true,
+				HealthcheckHTTPDefinition: goclient.HealthcheckHTTPDefinition{
+					ValidStatus: []uint{200},
+					Target:      "appclacks.com",
+					Method:      "GET",
+					Port:        443,
+					Protocol:    "https",
+				},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceHealthcheckHTTP().Schema, c.raw)
+			input := expandHealthcheckHTTPCreateInput(d, testExpandMeta())
+			if !reflect.DeepEqual(input, c.expected) {
+				t.Fatalf("expected %+v, got %+v", c.expected, input)
+			}
+		})
+	}
+}
+
+func TestExpandHealthcheckHTTPUpdateInput(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceHealthcheckHTTP().Schema, map[string]interface{}{
+		resHealthcheckName:            "tf_acc_http",
+		resHealthcheckInterval:        "60s",
+		resHealthcheckTimeout:         "10s",
+		resHealthcheckTarget:          "appclacks.com",
+		resHealthcheckPort:            80,
+		resHealthcheckHTTPValidStatus: []interface{}{200},
+		resHealthcheckHTTPMethod:      "head",
+		resHealthcheckHTTPProtocol:    "http",
+	})
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	input, err := expandHealthcheckHTTPUpdateInput(context.Background(), d, &providerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := goclient.UpdateHTTPHealthcheckInput{
+		ID:       "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:     "tf_acc_http",
+		Interval: "60s",
+		Timeout:  "10s",
+		Enabled:  true,
+		HealthcheckHTTPDefinition: goclient.HealthcheckHTTPDefinition{
+			ValidStatus: []uint{200},
+			Target:      "appclacks.com",
+			Method:      "HEAD",
+			Port:        80,
+			Protocol:    "http",
+		},
+	}
+	if !reflect.DeepEqual(input, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, input)
+	}
+}
+
+func TestResourceHealthcheckHTTPUpdateClearsFields(t *testing.T) {
+	api, server := newFakeAPI(t)
+	c, err := goclient.New(goclient.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := &providerConfig{client: &Client{client: c, limiter: newLimiter(0, 0)}}
+	r := resourceHealthcheckHTTP()
+	required := map[string]interface{}{
+		resHealthcheckName:            "tf_acc_http",
+		resHealthcheckInterval:        "60s",
+		resHealthcheckTimeout:         "10s",
+		resHealthcheckTarget:          "appclacks.com",
+		resHealthcheckPort:            443,
+		resHealthcheckHTTPMethod:      "GET",
+		resHealthcheckHTTPProtocol:    "https",
+		resHealthcheckHTTPValidStatus: []interface{}{200},
+	}
+	raw := map[string]interface{}{
+		resHealthcheckDescription:    "foo",
+		resHealthcheckLabels:         map[string]interface{}{"env": "prod"},
+		resHealthcheckHTTPHost:       "appclacks.com",
+		resHealthcheckHTTPPath:       "/healthz",
+		resHealthcheckHTTPBody:       "ping",
+		resHealthcheckHTTPBodyRegexp: []interface{}{"pong"},
+		resHealthcheckHTTPHeaders:    map[string]interface{}{"X-Check": "1"},
+		resHealthcheckHTTPQuery:      map[string]interface{}{"verbose": "true"},
+	}
+	for k, v := range required {
+		raw[k] = v
+	}
+	d := schema.TestResourceDataRaw(t, r.Schema, raw)
+	if diags := resourceHealthcheckHTTPCreate(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("failed to create the health check: %v", diags)
+	}
+
+	// the fake API keeps the fields missing from the update payloads
+	id := d.Id()
+	d = schema.TestResourceDataRaw(t, r.Schema, required)
+	d.SetId(id)
+	if diags := resourceHealthcheckHTTPUpdate(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("failed to update the health check: %v", diags)
+	}
+	healthcheck := api.healthchecks[d.Id()]
+	if healthcheck.Description != "" || len(healthcheck.Labels) != 0 {
+		t.Fatalf("expected the description and labels to be cleared, got %+v", healthcheck)
+	}
+	definition := healthcheck.Definition.(goclient.HealthcheckHTTPDefinition)
+	if definition.Host != "" || definition.Path != "" || definition.Body != "" || len(definition.BodyRegexp) != 0 || len(definition.Headers) != 0 || len(definition.Query) != 0 {
+		t.Fatalf("expected the optional fields to be cleared, got %+v", definition)
+	}
+	for _, k := range []string{resHealthcheckDescription, resHealthcheckHTTPHost, resHealthcheckHTTPPath, resHealthcheckHTTPBody} {
+		if v := d.Get(k).(string); v != "" {
+			t.Fatalf("expected %s to be cleared in the state, got %q", k, v)
+		}
+	}
+	if d.Get(resHealthcheckHTTPBodyRegexp).(*schema.Set).Len() != 0 || len(d.Get(resHealthcheckHTTPHeaders).(map[string]interface{})) != 0 || len(d.Get(resHealthcheckHTTPQuery).(map[string]interface{})) != 0 {
+		t.Fatalf("expected the collections to be cleared in the state, got %v", d.State().Attributes)
+	}
+}
+
+func TestResourceHTTPHealthcheckApply(t *testing.T) {
+	r := resourceHealthcheckHTTP()
+	d := r.TestResourceData()
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	err := resourceHTTPHealthcheckApply(context.Background(), d, testExpandMeta(), &goclient.Healthcheck{
+		ID:          "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:        "tf_acc_http",
+		Description: "foo",
+		Labels:      map[string]string{"env": "prod", "team": "sre"},
+		Type:        "http",
+		Interval:    "30s",
+		Timeout:     "5s",
+		Enabled:     true,
+		CreatedAt:   time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+		Definition: goclient.HealthcheckHTTPDefinition{
+			ValidStatus: []uint{201, 200},
+			Target:      "appclacks.com",
+			Method:      "POST",
+			Port:        443,
+			Host:        "api.appclacks.com",
+			Redirect:    true,
+			Query:       map[string]string{"foo": "bar"},
+			Body:        "{}",
+			BodyRegexp:  []string{"ok"},
+			Headers:     map[string]string{"content-type": "application/json"},
+			Protocol:    "https",
+			Path:        "/healthz",
+			Key:         "key",
+			Cert:        "cert",
+			Cacert:      "cacert",
+			Insecure:    true,
+			ServerName:  "api.appclacks.com",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCheckResourceDataValues(t, r, d, map[string]interface{}{
+		resHealthcheckName:            "tf_acc_http",
+		resHealthcheckDescription:     "foo",
+		resHealthcheckLabels:          map[string]interface{}{"env": "prod"},
+		resHealthcheckLabelsAll:       map[string]interface{}{"env": "prod", "team": "sre"},
+		resHealthcheckID:              "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		resHealthcheckType:            "http",
+		resHealthcheckCreatedAt:       "2023-01-02T15:04:05Z",
+		resHealthcheckInterval:        "30s",
+		resHealthcheckTimeout:         "5s",
+		resHealthcheckEnabled:         true,
+		resHealthcheckTarget:          "appclacks.com",
+		resHealthcheckPort:            443,
+		resHealthcheckHTTPValidStatus: []interface{}{200, 201},
+		resHealthcheckHTTPMethod:      "POST",
+		resHealthcheckHTTPProtocol:    "https",
+		resHealthcheckHTTPPath:        "/healthz",
+		resHealthcheckHTTPRedirect:    true,
+		resHealthcheckHTTPBody:        "{}",
+		resHealthcheckHTTPBodyRegexp:  []interface{}{"ok"},
+		resHealthcheckHTTPHeaders:     map[string]interface{}{"Content-Type": "application/json"},
+		resHealthcheckHTTPQuery:       map[string]interface{}{"foo": "bar"},
+		resHealthcheckHTTPHost:        "api.appclacks.com",
+		resHealthcheckTLSKey:          "key",
+		resHealthcheckTLSCert:         "cert",
+		resHealthcheckTLSCacert:       "cacert",
+		resHealthcheckTLSServerName:   "api.appclacks.com",
+		resHealthcheckTLSInsecure:     true,
+	})
+
+	err = resourceHTTPHealthcheckApply(context.Background(), d, testExpandMeta(), &goclient.Healthcheck{
+		Type:       "tcp",
+		Definition: goclient.HealthcheckTCPDefinition{},
+	})
+	if err == nil || err.Error() != "Invalid healthcheck type. Expecting http, got tcp" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
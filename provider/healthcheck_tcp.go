@@ -7,7 +7,9 @@ import (
 
 	goclient "github.com/appclacks/go-client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const (
@@ -31,22 +33,48 @@ func resourceHealthcheckTCP() *schema.Resource {
 				Description: "Health check description",
 			},
 			resHealthcheckLabels: {
+				Type:             schema.TypeMap,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				Optional:         true,
+				ValidateDiagFunc: validateLabels,
+				Description:      "Health check labels. Keys and values must be between 1 and 255 characters",
+			},
+			resHealthcheckLabelsAll: {
 				Type:        schema.TypeMap,
 				Elem:        &schema.Schema{Type: schema.TypeString},
-				Optional:    true,
-				Description: "Health check labels",
+				Computed:    true,
+				Description: "All labels of the health check, including the provider default labels",
 			},
-			resHealthcheckInterval: {
+			resHealthcheckID: {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     defaultHealthcheckInterval,
-				Description: "Health check interval (example: 30s)",
+				Computed:    true,
+				Description: "Health check ID",
 			},
-			resHealthcheckTimeout: {
+			resHealthcheckType: {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     defaultHealthcheckTimeout,
-				Description: "Health check timeout (example: 5s)",
+				Computed:    true,
+				Description: "Health check type",
+			},
+			resHealthcheckCreatedAt: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Health check creation date (RFC 3339)",
+			},
+			resHealthcheckInterval: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Description:      "Health check interval (example: 30s). Equivalent durations (1m and 60s) do not produce a diff. Defaults to the provider `healthcheck_defaults` value or 60s",
+			},
+			resHealthcheckTimeout: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Description:      "Health check timeout (example: 5s). Defaults to the provider `healthcheck_defaults` value or 10s",
 			},
 			resHealthcheckEnabled: {
 				Type:        schema.TypeBool,
@@ -55,14 +83,16 @@ func resourceHealthcheckTCP() *schema.Resource {
 				Description: "Enable the health check on the Appclacks platform",
 			},
 			resHealthcheckTarget: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Health check target (can be a domain or an IP address)",
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateHostnameOrIP,
+				Description:      "Health check target (can be a domain or an IP address)",
 			},
 			resHealthcheckPort: {
-				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "Health check port",
+				Type:             schema.TypeInt,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsPortNumber),
+				Description:      "Health check port",
 			},
 			resHealthcheckTCPShouldFail: {
 				Type:        schema.TypeBool,
@@ -77,6 +107,13 @@ func resourceHealthcheckTCP() *schema.Resource {
 		UpdateContext: resourceHealthcheckTCPUpdate,
 		DeleteContext: resourceHealthcheckTCPDelete,
 
+		CustomizeDiff: customdiff.Sequence(
+			resourceHealthcheckLabelsCustomizeDiff,
+			resourceHealthcheckDefaultsCustomizeDiff("tcp"),
+			resourceHealthcheckDurationsCustomizeDiff,
+			resourceHealthcheckTimeoutsCustomizeDiff,
+		),
+
 		Importer: &schema.ResourceImporter{},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -89,52 +126,50 @@ func resourceHealthcheckTCP() *schema.Resource {
 }
 
 func resourceHealthcheckTCPUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutUpdate))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
-	update := goclient.UpdateTCPHealthcheckInput{
-		ID:       d.Id(),
-		Name:     d.Get(resHealthcheckName).(string),
-		Interval: d.Get(resHealthcheckInterval).(string),
-		Timeout:  d.Get(resHealthcheckTimeout).(string),
-		Enabled:  d.Get(resHealthcheckEnabled).(bool),
-		HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
-			Target: d.Get(resHealthcheckTarget).(string),
-			Port:   uint(d.Get(resHealthcheckPort).(int)),
-		},
-	}
-
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		update.Description = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTCPShouldFail); ok {
-		update.HealthcheckTCPDefinition.ShouldFail = v.(bool)
-	}
-	if l, ok := d.GetOk(resHealthcheckLabels); ok {
-		labels := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			labels[k] = v.(string)
-		}
-		update.Labels = labels
+	update, err := expandHealthcheckTCPUpdateInput(ctx, d, meta)
+	if err != nil {
+		return client.diagnostics(err)
 	}
 
 	if _, err := client.UpdateTCPHealthcheck(ctx, update); err != nil {
-		return diag.FromErr(err)
+		return client.diagnostics(err)
 	}
 
 	return resourceHealthcheckTCPRead(ctx, d, meta)
 }
 
+// expandHealthcheckTCPUpdateInput builds the payload updating the health check.
+// All optional fields are sent, so the fields removed from the configuration are cleared.
+func expandHealthcheckTCPUpdateInput(ctx context.Context, d *schema.ResourceData, meta interface{}) (goclient.UpdateTCPHealthcheckInput, error) {
+	labels, err := expandHealthcheckUpdateLabels(ctx, d, meta)
+	if err != nil {
+		return goclient.UpdateTCPHealthcheckInput{}, err
+	}
+	return goclient.UpdateTCPHealthcheckInput{
+		ID:                       d.Id(),
+		Name:                     d.Get(resHealthcheckName).(string),
+		Description:              d.Get(resHealthcheckDescription).(string),
+		Labels:                   labels,
+		Interval:                 d.Get(resHealthcheckInterval).(string),
+		Timeout:                  d.Get(resHealthcheckTimeout).(string),
+		Enabled:                  d.Get(resHealthcheckEnabled).(bool),
+		HealthcheckTCPDefinition: expandHealthcheckTCPDefinition(d),
+	}, nil
+}
+
 func resourceHealthcheckTCPDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
 	healthcheckID := d.Id()
 	_, err := client.DeleteHealthcheck(ctx, goclient.DeleteHealthcheckInput{ID: healthcheckID})
-	if err != nil {
-		return diag.FromErr(err)
+	if err != nil && !errors.Is(err, goclient.ErrNotFound) {
+		return client.diagnostics(err)
 	}
 
 	return nil
@@ -146,43 +181,39 @@ func resourceHealthcheckTCPCreate(ctx context.Context, d *schema.ResourceData, m
 
 	client := GetAppclacksClient(meta)
 
-	healthcheck := goclient.CreateTCPHealthcheckInput{
-		Name:     d.Get(resHealthcheckName).(string),
-		Interval: d.Get(resHealthcheckInterval).(string),
-		Timeout:  d.Get(resHealthcheckTimeout).(string),
-		Enabled:  d.Get(resHealthcheckEnabled).(bool),
-		HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
-			Target: d.Get(resHealthcheckTarget).(string),
-			Port:   uint(d.Get(resHealthcheckPort).(int)),
-		},
+	result, err := client.CreateTCPHealthcheck(ctx, expandHealthcheckTCPCreateInput(d, meta))
+	if err != nil {
+		return client.diagnostics(err)
 	}
 
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		healthcheck.Description = v.(string)
-	}
-	if l, ok := d.GetOk(resHealthcheckLabels); ok {
-		labels := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			labels[k] = v.(string)
-		}
-		healthcheck.Labels = labels
-	}
+	d.SetId(result.ID)
+	return resourceHealthcheckTCPRead(ctx, d, meta)
+}
 
-	if v, ok := d.GetOk(resHealthcheckTCPShouldFail); ok {
-		healthcheck.HealthcheckTCPDefinition.ShouldFail = v.(bool)
+// expandHealthcheckTCPCreateInput builds the payload creating the health check
+func expandHealthcheckTCPCreateInput(d *schema.ResourceData, meta interface{}) goclient.CreateTCPHealthcheckInput {
+	return goclient.CreateTCPHealthcheckInput{
+		Name:                     d.Get(resHealthcheckName).(string),
+		Description:              d.Get(resHealthcheckDescription).(string),
+		Labels:                   expandHealthcheckLabels(d, meta),
+		Interval:                 d.Get(resHealthcheckInterval).(string),
+		Timeout:                  d.Get(resHealthcheckTimeout).(string),
+		Enabled:                  d.Get(resHealthcheckEnabled).(bool),
+		HealthcheckTCPDefinition: expandHealthcheckTCPDefinition(d),
 	}
+}
 
-	result, err := client.CreateTCPHealthcheck(ctx, healthcheck)
-	if err != nil {
-		return diag.FromErr(err)
+// expandHealthcheckTCPDefinition builds the TCP definition shared by the create and update payloads
+func expandHealthcheckTCPDefinition(d *schema.ResourceData) goclient.HealthcheckTCPDefinition {
+	return goclient.HealthcheckTCPDefinition{
+		Target:     d.Get(resHealthcheckTarget).(string),
+		Port:       uint(d.Get(resHealthcheckPort).(int)),
+		ShouldFail: d.Get(resHealthcheckTCPShouldFail).(bool),
 	}
-
-	d.SetId(result.ID)
-	return resourceHealthcheckTCPRead(ctx, d, meta)
 }
 
 func resourceHealthcheckTCPRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutRead))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
@@ -195,12 +226,12 @@ func resourceHealthcheckTCPRead(ctx context.Context, d *schema.ResourceData, met
 			d.SetId("")
 			return nil
 		}
-		return diag.FromErr(err)
+		return client.diagnostics(err)
 	}
-	return diag.FromErr(resourceTCPHealthcheckApply(ctx, d, &result))
+	return diag.FromErr(resourceTCPHealthcheckApply(ctx, d, meta, &result))
 }
 
-func resourceTCPHealthcheckApply(_ context.Context, d *schema.ResourceData, healthcheck *goclient.Healthcheck) error {
+func resourceTCPHealthcheckApply(_ context.Context, d *schema.ResourceData, meta interface{}, healthcheck *goclient.Healthcheck) error {
 
 	if healthcheck.Type != "tcp" {
 		return fmt.Errorf("Invalid healthcheck type. Expecting tcp, got %s", healthcheck.Type)
@@ -210,21 +241,23 @@ func resourceTCPHealthcheckApply(_ context.Context, d *schema.ResourceData, heal
 		return err
 	}
 
-	if healthcheck.Description != "" {
-		if err := d.Set(resHealthcheckDescription, healthcheck.Description); err != nil {
-			return err
-		}
+	if err := setHealthcheckMetadata(d, healthcheck); err != nil {
+		return err
+	}
+
+	if err := d.Set(resHealthcheckDescription, healthcheck.Description); err != nil {
+		return err
 	}
 
-	if err := d.Set(resHealthcheckLabels, healthcheck.Labels); err != nil {
+	if err := setHealthcheckLabels(d, meta, healthcheck.Labels); err != nil {
 		return err
 	}
 
-	if err := d.Set(resHealthcheckInterval, healthcheck.Interval); err != nil {
+	if err := setDuration(d, resHealthcheckInterval, healthcheck.Interval); err != nil {
 		return err
 	}
 
-	if err := d.Set(resHealthcheckTimeout, healthcheck.Timeout); err != nil {
+	if err := setDuration(d, resHealthcheckTimeout, healthcheck.Timeout); err != nil {
 		return err
 	}
 
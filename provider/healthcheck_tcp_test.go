@@ -3,11 +3,13 @@ package provider
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
 	goclient "github.com/appclacks/go-client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -38,6 +40,14 @@ resource "appclacks_healthcheck_tcp" "check_tcp" {
   target = "google.fr"
   port = 80
 }
+`
+
+	testAccResourceHealthcheckTCPConfigRemoveOptional = `
+resource "appclacks_healthcheck_tcp" "check_tcp" {
+  name = "tf_acc_tcp2"
+  target = "google.fr"
+  port = 80
+}
 `
 )
 
@@ -62,6 +72,9 @@ func TestAccResourceHealthcheckTCP(t *testing.T) {
 						"labels.check": validateString("tcp"),
 						"target":       validateString("google.com"),
 					}),
+					resource.TestCheckResourceAttr("appclacks_healthcheck_tcp.check_tcp", "type", "tcp"),
+					resource.TestCheckResourceAttrPair("appclacks_healthcheck_tcp.check_tcp", "healthcheck_id", "appclacks_healthcheck_tcp.check_tcp", "id"),
+					resource.TestCheckResourceAttrSet("appclacks_healthcheck_tcp.check_tcp", "created_at"),
 				),
 			},
 			{
@@ -99,6 +112,18 @@ func TestAccResourceHealthcheckTCP(t *testing.T) {
 						s[0].Attributes)
 				},
 			},
+			{
+				Config: testAccResourceHealthcheckTCPConfigRemoveOptional,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceCheckExists("appclacks_healthcheck_tcp.check_tcp", check),
+					testAccCheckResourceCheck(check),
+					testAccCheckResourceTCPCheckAttributes(testAttrs{
+						"description": validateString(""),
+						"should_fail": validateString("false"),
+					}),
+					resource.TestCheckNoResourceAttr("appclacks_healthcheck_tcp.check_tcp", "labels.%"),
+				),
+			},
 		},
 	})
 }
@@ -140,3 +165,147 @@ func testAccCheckResourceTCPCheckDestroy(s *terraform.State) error {
 
 	return errors.New("TCP Health check still exists")
 }
+
+func TestExpandHealthcheckTCPCreateInput(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected goclient.CreateTCPHealthcheckInput
+	}{
+		{
+			name: "all attributes",
+			raw: map[string]interface{}{
+				resHealthcheckName:          "tf_acc_tcp",
+				resHealthcheckDescription:   "foo",
+				resHealthcheckLabels:        map[string]interface{}{"env": "prod"},
+				resHealthcheckInterval:      "30s",
+				resHealthcheckTimeout:       "5s",
+				resHealthcheckEnabled:       false,
+				resHealthcheckTarget:        "appclacks.com",
+				resHealthcheckPort:          443,
+				resHealthcheckTCPShouldFail: true,
+			},
+			expected: goclient.CreateTCPHealthcheckInput{
+				Name:        "tf_acc_tcp",
+				Description: "foo",
+				Labels:      map[string]string{"env": "prod", "team": "sre"},
+				Interval:    "30s",
+				Timeout:     "5s",
+				Enabled:     false,
+				HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+					Target:     "appclacks.com",
+					Port:       443,
+					ShouldFail: true,
+				},
+			},
+		},
+		{
+			name: "required attributes",
+			raw: map[string]interface{}{
+				resHealthcheckName:     "tf_acc_tcp",
+				resHealthcheckInterval: "60s",
+				resHealthcheckTimeout:  "10s",
+				resHealthcheckTarget:   "10.0.0.1",
+				resHealthcheckPort:     22,
+			},
+			expected: goclient.CreateTCPHealthcheckInput{
+				Name:     "tf_acc_tcp",
+				Labels:   map[string]string{"team": "sre"},
+				Interval: "60s",
+				Timeout:  "10s",
+				Enabled:  true,
+				HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+					Target: "10.0.0.1",
+					Port:   22,
+				},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceHealthcheckTCP().Schema, c.raw)
+			input := expandHealthcheckTCPCreateInput(d, testExpandMeta())
+			if !reflect.DeepEqual(input, c.expected) {
+				t.Fatalf("expected %+v, got %+v", c.expected, input)
+			}
+		})
+	}
+}
+
+func TestExpandHealthcheckTCPUpdateInput(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceHealthcheckTCP().Schema, map[string]interface{}{
+		resHealthcheckName:     "tf_acc_tcp",
+		resHealthcheckInterval: "60s",
+		resHealthcheckTimeout:  "10s",
+		resHealthcheckEnabled:  false,
+		resHealthcheckTarget:   "appclacks.com",
+		resHealthcheckPort:     443,
+	})
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	input, err := expandHealthcheckTCPUpdateInput(context.Background(), d, &providerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := goclient.UpdateTCPHealthcheckInput{
+		ID:       "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:     "tf_acc_tcp",
+		Interval: "60s",
+		Timeout:  "10s",
+		Enabled:  false,
+		HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+			Target: "appclacks.com",
+			Port:   443,
+		},
+	}
+	if !reflect.DeepEqual(input, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, input)
+	}
+}
+
+func TestResourceTCPHealthcheckApply(t *testing.T) {
+	r := resourceHealthcheckTCP()
+	d := r.TestResourceData()
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	err := resourceTCPHealthcheckApply(context.Background(), d, testExpandMeta(), &goclient.Healthcheck{
+		ID:          "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:        "tf_acc_tcp",
+		Description: "foo",
+		Labels:      map[string]string{"env": "prod", "team": "sre"},
+		Type:        "tcp",
+		Interval:    "30s",
+		Timeout:     "5s",
+		Enabled:     true,
+		CreatedAt:   time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+		Definition: goclient.HealthcheckTCPDefinition{
+			Target:     "appclacks.com",
+			Port:       443,
+			ShouldFail: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCheckResourceDataValues(t, r, d, map[string]interface{}{
+		resHealthcheckName:          "tf_acc_tcp",
+		resHealthcheckDescription:   "foo",
+		resHealthcheckLabels:        map[string]interface{}{"env": "prod"},
+		resHealthcheckLabelsAll:     map[string]interface{}{"env": "prod", "team": "sre"},
+		resHealthcheckID:            "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		resHealthcheckType:          "tcp",
+		resHealthcheckCreatedAt:     "2023-01-02T15:04:05Z",
+		resHealthcheckInterval:      "30s",
+		resHealthcheckTimeout:       "5s",
+		resHealthcheckEnabled:       true,
+		resHealthcheckTarget:        "appclacks.com",
+		resHealthcheckPort:          443,
+		resHealthcheckTCPShouldFail: true,
+	})
+
+	err = resourceTCPHealthcheckApply(context.Background(), d, testExpandMeta(), &goclient.Healthcheck{
+		Type:       "http",
+		Definition: goclient.HealthcheckHTTPDefinition{},
+	})
+	if err == nil || err.Error() != "Invalid healthcheck type. Expecting tcp, got http" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestSetHealthcheckMetadata(t *testing.T) {
+	r := resourceHealthcheckTCP()
+	d := r.TestResourceData()
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	err := resourceTCPHealthcheckApply(context.Background(), d, &providerConfig{}, &goclient.Healthcheck{
+		ID:        "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:      "tf_acc_tcp",
+		Type:      "tcp",
+		Interval:  "60s",
+		Timeout:   "10s",
+		CreatedAt: time.Date(2023, 1, 2, 15, 4, 5, 0, time.FixedZone("CET", 3600)),
+		Definition: goclient.HealthcheckTCPDefinition{
+			Target: "appclacks.com",
+			Port:   443,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		resHealthcheckID:        "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		resHealthcheckType:      "tcp",
+		resHealthcheckCreatedAt: "2023-01-02T14:04:05Z",
+	}
+	for k, v := range expected {
+		if result := d.Get(k).(string); result != v {
+			t.Fatalf("expected %s for %s, got %s", v, k, result)
+		}
+	}
+
+	// the computed attributes are kept in the plan of an update
+	config := testRawConfig(t, r, map[string]interface{}{
+		resHealthcheckName:   "tf_acc_tcp",
+		resHealthcheckTarget: "appclacks.fr",
+		resHealthcheckPort:   443,
+	})
+	state := d.State()
+	state.RawConfig = config
+	meta := &providerConfig{
+		healthcheckDefaults: expandHealthcheckDefaults(nil),
+	}
+	diff, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigShimmed(config, r.CoreConfigSchema()), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := diff.Attributes[resHealthcheckTarget]; !ok {
+		t.Fatal("expected a diff on the target")
+	}
+	for k := range expected {
+		if attr, ok := diff.Attributes[k]; ok && (attr.NewComputed || attr.New != attr.Old) {
+			t.Fatalf("unexpected diff for %s: %+v", k, attr)
+		}
+	}
+}
+
+func TestResourceHealthcheckDeleteNotFound(t *testing.T) {
+	definitions := map[string]string{
+		"command": `"command":"ls"`,
+		"dns":     `"domain":"appclacks.com"`,
+		"http":    `"target":"appclacks.com","port":443,"method":"GET","protocol":"https","valid-status":[200]`,
+		"tcp":     `"target":"appclacks.com","port":443`,
+		"tls":     `"target":"appclacks.com","port":443`,
+	}
+	resources := map[string]*schema.Resource{
+		"command": resourceHealthcheckCommand(),
+		"dns":     resourceHealthcheckDNS(),
+		"http":    resourceHealthcheckHTTP(),
+		"tcp":     resourceHealthcheckTCP(),
+		"tls":     resourceHealthcheckTLS(),
+	}
+	for healthcheckType, r := range resources {
+		id := "b6dd6bfc-8a75-11ed-a1eb-0242ac120002"
+		var lock sync.Mutex
+		exists := true
+		client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+			if !exists || !strings.HasSuffix(req.URL.Path, id) {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"messages":["health check not found"]}`)
+				return
+			}
+			switch req.Method {
+			case http.MethodGet:
+				fmt.Fprintf(w, `{"id":"%s","name":"tf_acc_%s","type":"%s","interval":"60s","timeout":"10s",%s}`, id, healthcheckType, healthcheckType, definitions[healthcheckType])
+			case http.MethodDelete:
+				exists = false
+				fmt.Fprint(w, `{"messages":["health check deleted"]}`)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}))
+		meta := &providerConfig{client: client}
+
+		d := r.TestResourceData()
+		d.SetId(id)
+		if diags := r.ReadContext(context.Background(), d, meta); diags.HasError() {
+			t.Fatalf("%s: unexpected error %v", healthcheckType, diags)
+		}
+		if d.Id() != id {
+			t.Fatalf("%s: the health check should exist", healthcheckType)
+		}
+
+		// the health check is removed outside of Terraform between the refresh and the apply
+		lock.Lock()
+		exists = false
+		lock.Unlock()
+
+		if diags := r.DeleteContext(context.Background(), d, meta); diags.HasError() {
+			t.Fatalf("%s: unexpected error %v", healthcheckType, diags)
+		}
+	}
+}
+
+func TestResourceHealthcheckDeleteError(t *testing.T) {
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"messages":["forbidden"]}`)
+	}))
+	r := resourceHealthcheckTCP()
+	d := r.TestResourceData()
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	if diags := r.DeleteContext(context.Background(), d, &providerConfig{client: client}); !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+}
+
+// testResourceDataValues returns the value of every attribute of the resource.
+// Sets are converted to lists sorted by their string representation.
+func testResourceDataValues(r *schema.Resource, d *schema.ResourceData) map[string]interface{} {
+	values := make(map[string]interface{}, len(r.Schema))
+	for k := range r.Schema {
+		v := d.Get(k)
+		if set, ok := v.(*schema.Set); ok {
+			list := set.List()
+			sort.Slice(list, func(i, j int) bool {
+				return fmt.Sprint(list[i]) < fmt.Sprint(list[j])
+			})
+			v = list
+		}
+		values[k] = v
+	}
+	return values
+}
+
+// testCheckResourceDataValues checks the value of every attribute of the resource
+func testCheckResourceDataValues(t *testing.T, r *schema.Resource, d *schema.ResourceData, expected map[string]interface{}) {
+	t.Helper()
+	values := testResourceDataValues(r, d)
+	for k, v := range expected {
+		if _, ok := r.Schema[k]; !ok {
+			t.Fatalf("unknown attribute %s", k)
+		}
+		if !reflect.DeepEqual(values[k], v) {
+			t.Errorf("expected %#v for %s, got %#v", v, k, values[k])
+		}
+	}
+	for k := range values {
+		if _, ok := expected[k]; !ok {
+			t.Errorf("attribute %s is not checked", k)
+		}
+	}
+}
+
+// testExpandMeta returns a provider configuration with default labels
+func testExpandMeta() *providerConfig {
+	return &providerConfig{
+		defaultLabels: map[string]string{"team": "sre"},
+	}
+}
@@ -7,7 +7,9 @@ import (
 
 	goclient "github.com/appclacks/go-client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const (
@@ -21,7 +23,7 @@ const (
 
 func resourceHealthcheckTLS() *schema.Resource {
 	return &schema.Resource{
-		Description: "Create a TLS connection on the target",
+		Description: "Create a TLS connection on the target. The TLS settings ignored by the prober are reported as warnings by terraform plan and apply",
 		Schema: map[string]*schema.Schema{
 			resHealthcheckName: {
 				Type:        schema.TypeString,
@@ -34,22 +36,48 @@ func resourceHealthcheckTLS() *schema.Resource {
 				Description: "Health check description",
 			},
 			resHealthcheckLabels: {
+				Type:             schema.TypeMap,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				Optional:         true,
+				ValidateDiagFunc: validateLabels,
+				Description:      "Health check labels. Keys and values must be between 1 and 255 characters",
+			},
+			resHealthcheckLabelsAll: {
 				Type:        schema.TypeMap,
 				Elem:        &schema.Schema{Type: schema.TypeString},
-				Optional:    true,
-				Description: "Health check labels",
+				Computed:    true,
+				Description: "All labels of the health check, including the provider default labels",
 			},
-			resHealthcheckInterval: {
+			resHealthcheckID: {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     defaultHealthcheckInterval,
-				Description: "Health check interval (example: 30s)",
+				Computed:    true,
+				Description: "Health check ID",
 			},
-			resHealthcheckTimeout: {
+			resHealthcheckType: {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     defaultHealthcheckTimeout,
-				Description: "Health check timeout (example: 5s)",
+				Computed:    true,
+				Description: "Health check type",
+			},
+			resHealthcheckCreatedAt: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Health check creation date (RFC 3339)",
+			},
+			resHealthcheckInterval: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Description:      "Health check interval (example: 30s). Equivalent durations (1m and 60s) do not produce a diff. Defaults to the provider `healthcheck_defaults` value or 60s",
+			},
+			resHealthcheckTimeout: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Description:      "Health check timeout (example: 5s). Defaults to the provider `healthcheck_defaults` value or 10s",
 			},
 			resHealthcheckEnabled: {
 				Type:        schema.TypeBool,
@@ -58,24 +86,26 @@ func resourceHealthcheckTLS() *schema.Resource {
 				Description: "Enable the health check on the Appclacks platform",
 			},
 			resHealthcheckTarget: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Health check target (can be a domain or an IP address)",
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateHostnameOrIP,
+				Description:      "Health check target (can be a domain or an IP address)",
 			},
 			resHealthcheckPort: {
-				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "Health check port",
+				Type:             schema.TypeInt,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsPortNumber),
+				Description:      "Health check port",
 			},
 			resHealthcheckTLSKey: {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "TLS key file to use for the TLS connection",
+				Description: "TLS key file to use for the TLS connection. Must be set together with cert",
 			},
 			resHealthcheckTLSCert: {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "TLS cert file to use for the TLS connection",
+				Description: "TLS cert file to use for the TLS connection. Must be set together with key",
 			},
 			resHealthcheckTLSCacert: {
 				Type:        schema.TypeString,
@@ -90,12 +120,15 @@ func resourceHealthcheckTLS() *schema.Resource {
 			resHealthcheckTLSInsecure: {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "Accept insecure TLS connections",
+				Description: "Accept insecure TLS connections. The expiration_delay is ignored when set to true",
 			},
 			resHealthcheckTLSExpirationDelay: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "The health check will be considered failed if hte certificate expires is less than this duration (for example: 168h)",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Description:      "The health check will be considered failed if hte certificate expires is less than this duration (for example: 168h)",
 			},
 		},
 
@@ -104,6 +137,14 @@ func resourceHealthcheckTLS() *schema.Resource {
 		UpdateContext: resourceHealthcheckTLSUpdate,
 		DeleteContext: resourceHealthcheckTLSDelete,
 
+		CustomizeDiff: customdiff.Sequence(
+			resourceHealthcheckLabelsCustomizeDiff,
+			resourceHealthcheckDefaultsCustomizeDiff("tls"),
+			resourceHealthcheckDurationsCustomizeDiff,
+			resourceHealthcheckTimeoutsCustomizeDiff,
+			resourceHealthcheckTLSCustomizeDiff,
+		),
+
 		Importer: &schema.ResourceImporter{},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -116,68 +157,50 @@ func resourceHealthcheckTLS() *schema.Resource {
 }
 
 func resourceHealthcheckTLSUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutUpdate))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
-	update := goclient.UpdateTLSHealthcheckInput{
-		ID:       d.Id(),
-		Name:     d.Get(resHealthcheckName).(string),
-		Interval: d.Get(resHealthcheckInterval).(string),
-		Timeout:  d.Get(resHealthcheckTimeout).(string),
-		Enabled:  d.Get(resHealthcheckEnabled).(bool),
-		HealthcheckTLSDefinition: goclient.HealthcheckTLSDefinition{
-			Target: d.Get(resHealthcheckTarget).(string),
-			Port:   uint(d.Get(resHealthcheckPort).(int)),
-		},
-	}
-
-	if l, ok := d.GetOk(resHealthcheckLabels); ok {
-		labels := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			labels[k] = v.(string)
-		}
-		update.Labels = labels
-	}
-
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		update.Description = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSKey); ok {
-		update.HealthcheckTLSDefinition.Key = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSCert); ok {
-		update.HealthcheckTLSDefinition.Cert = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSCacert); ok {
-		update.HealthcheckTLSDefinition.Cacert = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSServerName); ok {
-		update.HealthcheckTLSDefinition.ServerName = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSInsecure); ok {
-		update.HealthcheckTLSDefinition.Insecure = v.(bool)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSExpirationDelay); ok {
-		update.HealthcheckTLSDefinition.ExpirationDelay = v.(string)
+	update, err := expandHealthcheckTLSUpdateInput(ctx, d, meta)
+	if err != nil {
+		return client.diagnostics(err)
 	}
 
 	if _, err := client.UpdateTLSHealthcheck(ctx, update); err != nil {
-		return diag.FromErr(err)
+		return client.diagnostics(err)
 	}
 
 	return resourceHealthcheckTLSRead(ctx, d, meta)
 }
 
+// expandHealthcheckTLSUpdateInput builds the payload updating the health check.
+// All optional fields are sent, so the fields removed from the configuration are cleared.
+func expandHealthcheckTLSUpdateInput(ctx context.Context, d *schema.ResourceData, meta interface{}) (goclient.UpdateTLSHealthcheckInput, error) {
+	labels, err := expandHealthcheckUpdateLabels(ctx, d, meta)
+	if err != nil {
+		return goclient.UpdateTLSHealthcheckInput{}, err
+	}
+	return goclient.UpdateTLSHealthcheckInput{
+		ID:                       d.Id(),
+		Name:                     d.Get(resHealthcheckName).(string),
+		Description:              d.Get(resHealthcheckDescription).(string),
+		Labels:                   labels,
+		Interval:                 d.Get(resHealthcheckInterval).(string),
+		Timeout:                  d.Get(resHealthcheckTimeout).(string),
+		Enabled:                  d.Get(resHealthcheckEnabled).(bool),
+		HealthcheckTLSDefinition: expandHealthcheckTLSDefinition(d),
+	}, nil
+}
+
 func resourceHealthcheckTLSDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
 	healthcheckID := d.Id()
 	_, err := client.DeleteHealthcheck(ctx, goclient.DeleteHealthcheckInput{ID: healthcheckID})
-	if err != nil {
-		return diag.FromErr(err)
+	if err != nil && !errors.Is(err, goclient.ErrNotFound) {
+		return client.diagnostics(err)
 	}
 
 	return nil
@@ -189,61 +212,44 @@ func resourceHealthcheckTLSCreate(ctx context.Context, d *schema.ResourceData, m
 
 	client := GetAppclacksClient(meta)
 
-	healthcheck := goclient.CreateTLSHealthcheckInput{
-		Name:     d.Get(resHealthcheckName).(string),
-		Interval: d.Get(resHealthcheckInterval).(string),
-		Timeout:  d.Get(resHealthcheckTimeout).(string),
-		Enabled:  d.Get(resHealthcheckEnabled).(bool),
-		HealthcheckTLSDefinition: goclient.HealthcheckTLSDefinition{
-			Target: d.Get(resHealthcheckTarget).(string),
-			Port:   uint(d.Get(resHealthcheckPort).(int)),
-		},
+	result, err := client.CreateTLSHealthcheck(ctx, expandHealthcheckTLSCreateInput(d, meta))
+	if err != nil {
+		return client.diagnostics(err)
 	}
 
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		healthcheck.Description = v.(string)
-	}
-	if l, ok := d.GetOk(resHealthcheckLabels); ok {
-		labels := make(map[string]string)
-		for k, v := range l.(map[string]interface{}) {
-			labels[k] = v.(string)
-		}
-		healthcheck.Labels = labels
-	}
+	d.SetId(result.ID)
+	return resourceHealthcheckTLSRead(ctx, d, meta)
+}
 
-	if v, ok := d.GetOk(resHealthcheckDescription); ok {
-		healthcheck.Description = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSKey); ok {
-		healthcheck.HealthcheckTLSDefinition.Key = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSCert); ok {
-		healthcheck.HealthcheckTLSDefinition.Cert = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSCacert); ok {
-		healthcheck.HealthcheckTLSDefinition.Cacert = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSServerName); ok {
-		healthcheck.HealthcheckTLSDefinition.ServerName = v.(string)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSInsecure); ok {
-		healthcheck.HealthcheckTLSDefinition.Insecure = v.(bool)
-	}
-	if v, ok := d.GetOk(resHealthcheckTLSExpirationDelay); ok {
-		healthcheck.HealthcheckTLSDefinition.ExpirationDelay = v.(string)
+// expandHealthcheckTLSCreateInput builds the payload creating the health check
+func expandHealthcheckTLSCreateInput(d *schema.ResourceData, meta interface{}) goclient.CreateTLSHealthcheckInput {
+	return goclient.CreateTLSHealthcheckInput{
+		Name:                     d.Get(resHealthcheckName).(string),
+		Description:              d.Get(resHealthcheckDescription).(string),
+		Labels:                   expandHealthcheckLabels(d, meta),
+		Interval:                 d.Get(resHealthcheckInterval).(string),
+		Timeout:                  d.Get(resHealthcheckTimeout).(string),
+		Enabled:                  d.Get(resHealthcheckEnabled).(bool),
+		HealthcheckTLSDefinition: expandHealthcheckTLSDefinition(d),
 	}
+}
 
-	result, err := client.CreateTLSHealthcheck(ctx, healthcheck)
-	if err != nil {
-		return diag.FromErr(err)
+// expandHealthcheckTLSDefinition builds the TLS definition shared by the create and update payloads
+func expandHealthcheckTLSDefinition(d *schema.ResourceData) goclient.HealthcheckTLSDefinition {
+	return goclient.HealthcheckTLSDefinition{
+		Target:          d.Get(resHealthcheckTarget).(string),
+		Port:            uint(d.Get(resHealthcheckPort).(int)),
+		Key:             d.Get(resHealthcheckTLSKey).(string),
+		Cert:            d.Get(resHealthcheckTLSCert).(string),
+		Cacert:          d.Get(resHealthcheckTLSCacert).(string),
+		ServerName:      d.Get(resHealthcheckTLSServerName).(string),
+		Insecure:        d.Get(resHealthcheckTLSInsecure).(bool),
+		ExpirationDelay: d.Get(resHealthcheckTLSExpirationDelay).(string),
 	}
-
-	d.SetId(result.ID)
-	return resourceHealthcheckTLSRead(ctx, d, meta)
 }
 
 func resourceHealthcheckTLSRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutRead))
 	defer cancel()
 	client := GetAppclacksClient(meta)
 
@@ -256,36 +262,38 @@ func resourceHealthcheckTLSRead(ctx context.Context, d *schema.ResourceData, met
 			d.SetId("")
 			return nil
 		}
-		return diag.FromErr(err)
+		return client.diagnostics(err)
 	}
-	return diag.FromErr(resourceTLSHealthcheckApply(ctx, d, &result))
+	return diag.FromErr(resourceTLSHealthcheckApply(ctx, d, meta, &result))
 }
 
-func resourceTLSHealthcheckApply(_ context.Context, d *schema.ResourceData, healthcheck *goclient.Healthcheck) error {
+func resourceTLSHealthcheckApply(_ context.Context, d *schema.ResourceData, meta interface{}, healthcheck *goclient.Healthcheck) error {
 
 	if healthcheck.Type != "tls" {
-		return fmt.Errorf("Invalid healthcheck type. Expecting tcp, got %s", healthcheck.Type)
+		return fmt.Errorf("Invalid healthcheck type. Expecting tls, got %s", healthcheck.Type)
 	}
 
 	if err := d.Set(resHealthcheckName, healthcheck.Name); err != nil {
 		return err
 	}
 
-	if healthcheck.Description != "" {
-		if err := d.Set(resHealthcheckDescription, healthcheck.Description); err != nil {
-			return err
-		}
+	if err := setHealthcheckMetadata(d, healthcheck); err != nil {
+		return err
+	}
+
+	if err := d.Set(resHealthcheckDescription, healthcheck.Description); err != nil {
+		return err
 	}
 
-	if err := d.Set(resHealthcheckLabels, healthcheck.Labels); err != nil {
+	if err := setHealthcheckLabels(d, meta, healthcheck.Labels); err != nil {
 		return err
 	}
 
-	if err := d.Set(resHealthcheckInterval, healthcheck.Interval); err != nil {
+	if err := setDuration(d, resHealthcheckInterval, healthcheck.Interval); err != nil {
 		return err
 	}
 
-	if err := d.Set(resHealthcheckTimeout, healthcheck.Timeout); err != nil {
+	if err := setDuration(d, resHealthcheckTimeout, healthcheck.Timeout); err != nil {
 		return err
 	}
 
@@ -321,7 +329,7 @@ func resourceTLSHealthcheckApply(_ context.Context, d *schema.ResourceData, heal
 	if err := d.Set(resHealthcheckTLSInsecure, definition.Insecure); err != nil {
 		return err
 	}
-	if err := d.Set(resHealthcheckTLSExpirationDelay, definition.ExpirationDelay); err != nil {
+	if err := setDuration(d, resHealthcheckTLSExpirationDelay, definition.ExpirationDelay); err != nil {
 		return err
 	}
 
@@ -3,11 +3,13 @@ package provider
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
 	goclient "github.com/appclacks/go-client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -52,6 +54,14 @@ resource "appclacks_healthcheck_tls" "check_tls" {
   server_name = "google.fr"
   expiration_delay = "20s"
 }
+`
+
+	testAccResourceHealthcheckTLSConfigRemoveOptional = `
+resource "appclacks_healthcheck_tls" "check_tls" {
+  name = "tf_acc_tls2"
+  target = "google.fr"
+  port = 80
+}
 `
 )
 
@@ -82,6 +92,9 @@ func TestAccResourceHealthcheckTLS(t *testing.T) {
 						"server_name":      validateString("google.com"),
 						"expiration_delay": validateString("10s"),
 					}),
+					resource.TestCheckResourceAttr("appclacks_healthcheck_tls.check_tls", "type", "tls"),
+					resource.TestCheckResourceAttrPair("appclacks_healthcheck_tls.check_tls", "healthcheck_id", "appclacks_healthcheck_tls.check_tls", "id"),
+					resource.TestCheckResourceAttrSet("appclacks_healthcheck_tls.check_tls", "created_at"),
 				),
 			},
 			{
@@ -131,6 +144,23 @@ func TestAccResourceHealthcheckTLS(t *testing.T) {
 						s[0].Attributes)
 				},
 			},
+			{
+				Config: testAccResourceHealthcheckTLSConfigRemoveOptional,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckResourceCheckExists("appclacks_healthcheck_tls.check_tls", check),
+					testAccCheckResourceCheck(check),
+					testAccCheckResourceTLSCheckAttributes(testAttrs{
+						"description":      validateString(""),
+						"cert":             validateString(""),
+						"cacert":           validateString(""),
+						"key":              validateString(""),
+						"server_name":      validateString(""),
+						"insecure":         validateString("false"),
+						"expiration_delay": validateString(""),
+					}),
+					resource.TestCheckNoResourceAttr("appclacks_healthcheck_tls.check_tls", "labels.%"),
+				),
+			},
 		},
 	})
 }
@@ -172,3 +202,168 @@ func testAccCheckResourceTLSCheckDestroy(s *terraform.State) error {
 
 	return errors.New("TLS Health check still exists")
 }
+
+func TestExpandHealthcheckTLSCreateInput(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected goclient.CreateTLSHealthcheckInput
+	}{
+		{
+			name: "all attributes",
+			raw: map[string]interface{}{
+				resHealthcheckName:               "tf_acc_tls",
+				resHealthcheckDescription:        "foo",
+				resHealthcheckLabels:             map[string]interface{}{"env": "prod"},
+				resHealthcheckInterval:           "30s",
+				resHealthcheckTimeout:            "5s",
+				resHealthcheckEnabled:            false,
+				resHealthcheckTarget:             "appclacks.com",
+				resHealthcheckPort:               443,
+				resHealthcheckTLSKey:             "key",
+				resHealthcheckTLSCert:            "cert",
+				resHealthcheckTLSCacert:          "cacert",
+				resHealthcheckTLSServerName:      "api.appclacks.com",
+				resHealthcheckTLSInsecure:        true,
+				resHealthcheckTLSExpirationDelay: "168h",
+			},
+			expected: goclient.CreateTLSHealthcheckInput{
+				Name:        "tf_acc_tls",
+				Description: "foo",
+				Labels:      map[string]string{"env": "prod", "team": "sre"},
+				Interval:    "30s",
+				Timeout:     "5s",
+				Enabled:     false,
+				HealthcheckTLSDefinition: goclient.HealthcheckTLSDefinition{
+					Target:          "appclacks.com",
+					Port:            443,
+					Key:             "key",
+					Cert:            "cert",
+					Cacert:          "cacert",
+					ServerName:      "api.appclacks.com",
+					Insecure:        true,
+					ExpirationDelay: "168h",
+				},
+			},
+		},
+		{
+			name: "required attributes",
+			raw: map[string]interface{}{
+				resHealthcheckName:     "tf_acc_tls",
+				resHealthcheckInterval: "60s",
+				resHealthcheckTimeout:  "10s",
+				resHealthcheckTarget:   "appclacks.com",
+				resHealthcheckPort:     443,
+			},
+			expected: goclient.CreateTLSHealthcheckInput{
+				Name:     "tf_acc_tls",
+				Labels:   map[string]string{"team": "sre"},
+				Interval: "60s",
+				Timeout:  "10s",
+				Enabled:  true,
+				HealthcheckTLSDefinition: goclient.HealthcheckTLSDefinition{
+					Target: "appclacks.com",
+					Port:   443,
+				},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceHealthcheckTLS().Schema, c.raw)
+			input := expandHealthcheckTLSCreateInput(d, testExpandMeta())
+			if !reflect.DeepEqual(input, c.expected) {
+				t.Fatalf("expected %+v, got %+v", c.expected, input)
+			}
+		})
+	}
+}
+
+func TestExpandHealthcheckTLSUpdateInput(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceHealthcheckTLS().Schema, map[string]interface{}{
+		resHealthcheckName:               "tf_acc_tls",
+		resHealthcheckInterval:           "60s",
+		resHealthcheckTimeout:            "10s",
+		resHealthcheckTarget:             "appclacks.com",
+		resHealthcheckPort:               443,
+		resHealthcheckTLSExpirationDelay: "24h",
+	})
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	input, err := expandHealthcheckTLSUpdateInput(context.Background(), d, &providerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := goclient.UpdateTLSHealthcheckInput{
+		ID:       "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:     "tf_acc_tls",
+		Interval: "60s",
+		Timeout:  "10s",
+		Enabled:  true,
+		HealthcheckTLSDefinition: goclient.HealthcheckTLSDefinition{
+			Target:          "appclacks.com",
+			Port:            443,
+			ExpirationDelay: "24h",
+		},
+	}
+	if !reflect.DeepEqual(input, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, input)
+	}
+}
+
+func TestResourceTLSHealthcheckApply(t *testing.T) {
+	r := resourceHealthcheckTLS()
+	d := r.TestResourceData()
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	err := resourceTLSHealthcheckApply(context.Background(), d, testExpandMeta(), &goclient.Healthcheck{
+		ID:          "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:        "tf_acc_tls",
+		Description: "foo",
+		Labels:      map[string]string{"env": "prod", "team": "sre"},
+		Type:        "tls",
+		Interval:    "30s",
+		Timeout:     "5s",
+		Enabled:     true,
+		CreatedAt:   time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+		Definition: goclient.HealthcheckTLSDefinition{
+			Target:          "appclacks.com",
+			Port:            443,
+			Key:             "key",
+			Cert:            "cert",
+			Cacert:          "cacert",
+			ServerName:      "api.appclacks.com",
+			Insecure:        true,
+			ExpirationDelay: "168h0m0s",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCheckResourceDataValues(t, r, d, map[string]interface{}{
+		resHealthcheckName:               "tf_acc_tls",
+		resHealthcheckDescription:        "foo",
+		resHealthcheckLabels:             map[string]interface{}{"env": "prod"},
+		resHealthcheckLabelsAll:          map[string]interface{}{"env": "prod", "team": "sre"},
+		resHealthcheckID:                 "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		resHealthcheckType:               "tls",
+		resHealthcheckCreatedAt:          "2023-01-02T15:04:05Z",
+		resHealthcheckInterval:           "30s",
+		resHealthcheckTimeout:            "5s",
+		resHealthcheckEnabled:            true,
+		resHealthcheckTarget:             "appclacks.com",
+		resHealthcheckPort:               443,
+		resHealthcheckTLSKey:             "key",
+		resHealthcheckTLSCert:            "cert",
+		resHealthcheckTLSCacert:          "cacert",
+		resHealthcheckTLSServerName:      "api.appclacks.com",
+		resHealthcheckTLSInsecure:        true,
+		resHealthcheckTLSExpirationDelay: "168h0m0s",
+	})
+
+	err = resourceTLSHealthcheckApply(context.Background(), d, testExpandMeta(), &goclient.Healthcheck{
+		Type:       "http",
+		Definition: goclient.HealthcheckHTTPDefinition{},
+	})
+	if err == nil || err.Error() != "Invalid healthcheck type. Expecting tls, got http" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	resHealthcheckLabelsAll = "labels_all"
+
+	maxLabelLength = 255
+)
+
+// ignoreLabelsConfig contains the labels which are managed outside of Terraform
+type ignoreLabelsConfig struct {
+	keys        []string
+	keyPrefixes []string
+}
+
+func (c ignoreLabelsConfig) enabled() bool {
+	return len(c.keys) != 0 || len(c.keyPrefixes) != 0
+}
+
+func (c ignoreLabelsConfig) ignored(key string) bool {
+	for _, k := range c.keys {
+		if k == key {
+			return true
+		}
+	}
+	for _, prefix := range c.keyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateLabels checks that the labels keys and values are between 1 and 255 characters
+func validateLabels(i interface{}, path cty.Path) diag.Diagnostics {
+	labels, ok := i.(map[string]interface{})
+	if !ok {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid type",
+			Detail:        "Expected a map",
+			AttributePath: path,
+		}}
+	}
+	var diags diag.Diagnostics
+	for _, k := range sortedKeys(labels) {
+		keyPath := path.IndexString(k)
+		if len(k) == 0 || len(k) > maxLabelLength {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid label key",
+				Detail:        fmt.Sprintf("%s: label keys should be between 1 and %d characters", attributePathString(keyPath), maxLabelLength),
+				AttributePath: keyPath,
+			})
+		}
+		v, ok := labels[k].(string)
+		if !ok {
+			continue
+		}
+		if len(v) == 0 || len(v) > maxLabelLength {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid label value",
+				Detail:        fmt.Sprintf("%s: label values should be between 1 and %d characters, got %d", attributePathString(keyPath), maxLabelLength, len(v)),
+				AttributePath: keyPath,
+			})
+		}
+	}
+	return diags
+}
+
+// checkLabelKeys checks that the labels keys match the provider label_key_pattern
+func checkLabelKeys(pattern *regexp.Regexp, path cty.Path, labels map[string]interface{}) diag.Diagnostics {
+	if pattern == nil {
+		return nil
+	}
+	var diags diag.Diagnostics
+	for _, k := range sortedKeys(labels) {
+		if !pattern.MatchString(k) {
+			keyPath := path.IndexString(k)
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid label key",
+				Detail:        fmt.Sprintf("%s: the label key does not match the provider label_key_pattern %s", attributePathString(keyPath), pattern.String()),
+				AttributePath: keyPath,
+			})
+		}
+	}
+	return diags
+}
+
+// checkIgnoredLabels checks that the labels are not ignored by the provider ignore_labels.
+// An ignored label is removed from the state, so configuring it would produce a diff on every plan.
+func checkIgnoredLabels(ignore ignoreLabelsConfig, path cty.Path, labels map[string]interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, k := range sortedKeys(labels) {
+		if ignore.ignored(k) {
+			keyPath := path.IndexString(k)
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Ignored label",
+				Detail:        fmt.Sprintf("%s: the label is ignored by the provider ignore_labels, remove it from the labels or from ignore_labels", attributePathString(keyPath)),
+				AttributePath: keyPath,
+			})
+		}
+	}
+	return diags
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// expandHealthcheckLabels returns the labels to send to the API: the provider
+// default labels merged with the resource labels, the latter taking precedence
+func expandHealthcheckLabels(d *schema.ResourceData, meta interface{}) map[string]string {
+	config := getProviderConfig(meta)
+	labels := mergeLabels(config.defaultLabels, d.Get(resHealthcheckLabels).(map[string]interface{}))
+	if len(labels) == 0 {
+		return nil
+	}
+	return labels
+}
+
+// expandHealthcheckUpdateLabels returns the labels to send to the API on update.
+// The ignored labels currently set on the health check are preserved.
+func expandHealthcheckUpdateLabels(ctx context.Context, d *schema.ResourceData, meta interface{}) (map[string]string, error) {
+	config := getProviderConfig(meta)
+	labels := expandHealthcheckLabels(d, meta)
+	if !config.ignoreLabels.enabled() {
+		return labels, nil
+	}
+
+	current, err := config.client.GetHealthcheck(ctx, goclient.GetHealthcheckInput{
+		Identifier: d.Id(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range current.Labels {
+		if !config.ignoreLabels.ignored(k) {
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+	return labels, nil
+}
+
+// setHealthcheckLabels stores the labels returned by the API in the state.
+// Labels inherited from the provider default labels are only stored in labels_all
+// unless they are also configured on the resource, and ignored labels are not stored.
+func setHealthcheckLabels(d *schema.ResourceData, meta interface{}, labels map[string]string) error {
+	config := getProviderConfig(meta)
+	configured := d.Get(resHealthcheckLabels).(map[string]interface{})
+
+	allLabels := make(map[string]string)
+	resourceLabels := make(map[string]string)
+	for k, v := range labels {
+		if config.ignoreLabels.ignored(k) {
+			continue
+		}
+		allLabels[k] = v
+		if defaultValue, ok := config.defaultLabels[k]; ok && defaultValue == v {
+			if _, ok := configured[k]; !ok {
+				continue
+			}
+		}
+		resourceLabels[k] = v
+	}
+
+	if err := d.Set(resHealthcheckLabels, resourceLabels); err != nil {
+		return err
+	}
+
+	return d.Set(resHealthcheckLabelsAll, allLabels)
+}
+
+// resourceHealthcheckLabelsCustomizeDiff computes labels_all during the plan
+// so the effective set of labels is visible before apply. It also checks the
+// labels keys against the provider label_key_pattern and ignore_labels.
+func resourceHealthcheckLabelsCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if meta == nil {
+		return nil
+	}
+	if !d.NewValueKnown(resHealthcheckLabels) {
+		return d.SetNewComputed(resHealthcheckLabelsAll)
+	}
+	config := getProviderConfig(meta)
+	path := cty.GetAttrPath(resHealthcheckLabels)
+	configured := d.Get(resHealthcheckLabels).(map[string]interface{})
+	diags := checkLabelKeys(config.labelKeyPattern, path, configured)
+	diags = append(diags, checkIgnoredLabels(config.ignoreLabels, path, configured)...)
+	if diags.HasError() {
+		errs := make([]error, 0, len(diags))
+		for _, diagnostic := range diags {
+			errs = append(errs, errors.New(diagnostic.Detail))
+		}
+		return errors.Join(errs...)
+	}
+	labels := mergeLabels(config.defaultLabels, d.Get(resHealthcheckLabels).(map[string]interface{}))
+	for k := range labels {
+		if config.ignoreLabels.ignored(k) {
+			delete(labels, k)
+		}
+	}
+
+	current := make(map[string]string)
+	for k, v := range d.Get(resHealthcheckLabelsAll).(map[string]interface{}) {
+		current[k] = v.(string)
+	}
+	if reflect.DeepEqual(labels, current) {
+		return nil
+	}
+
+	return d.SetNew(resHealthcheckLabelsAll, labels)
+}
+
+func mergeLabels(defaultLabels map[string]string, labels map[string]interface{}) map[string]string {
+	result := make(map[string]string)
+	for k, v := range defaultLabels {
+		result[k] = v
+	}
+	for k, v := range labels {
+		result[k] = v.(string)
+	}
+	return result
+}
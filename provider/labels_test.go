@@ -0,0 +1,224 @@
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandHealthcheckLabels(t *testing.T) {
+	meta := &providerConfig{
+		defaultLabels: map[string]string{
+			"team": "core",
+			"env":  "prod",
+		},
+	}
+	d := schema.TestResourceDataRaw(t, resourceHealthcheckTCP().Schema, map[string]interface{}{
+		resHealthcheckLabels: map[string]interface{}{
+			"env":   "staging",
+			"check": "tcp",
+		},
+	})
+
+	expected := map[string]string{
+		"team":  "core",
+		"env":   "staging",
+		"check": "tcp",
+	}
+	labels := expandHealthcheckLabels(d, meta)
+	if !reflect.DeepEqual(labels, expected) {
+		t.Fatalf("expected labels %v, got %v", expected, labels)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceHealthcheckTCP().Schema, map[string]interface{}{})
+	if labels := expandHealthcheckLabels(d, &providerConfig{}); labels != nil {
+		t.Fatalf("expected nil labels, got %v", labels)
+	}
+}
+
+func TestSetHealthcheckLabels(t *testing.T) {
+	meta := &providerConfig{
+		defaultLabels: map[string]string{
+			"team":       "core",
+			"managed_by": "terraform",
+		},
+	}
+	d := schema.TestResourceDataRaw(t, resourceHealthcheckTCP().Schema, map[string]interface{}{
+		resHealthcheckLabels: map[string]interface{}{
+			"team":  "core",
+			"check": "tcp",
+		},
+	})
+
+	apiLabels := map[string]string{
+		"team":       "core",
+		"managed_by": "terraform",
+		"check":      "tcp",
+	}
+	if err := setHealthcheckLabels(d, meta, apiLabels); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"team":  "core",
+		"check": "tcp",
+	}
+	if labels := d.Get(resHealthcheckLabels).(map[string]interface{}); !reflect.DeepEqual(labels, expected) {
+		t.Fatalf("expected labels %v, got %v", expected, labels)
+	}
+	expectedAll := map[string]interface{}{
+		"team":       "core",
+		"managed_by": "terraform",
+		"check":      "tcp",
+	}
+	if labels := d.Get(resHealthcheckLabelsAll).(map[string]interface{}); !reflect.DeepEqual(labels, expectedAll) {
+		t.Fatalf("expected labels_all %v, got %v", expectedAll, labels)
+	}
+}
+
+func TestIgnoreLabels(t *testing.T) {
+	config := ignoreLabelsConfig{
+		keys:        []string{"silenced_by"},
+		keyPrefixes: []string{"oncall_"},
+	}
+	cases := map[string]bool{
+		"silenced_by":    true,
+		"silenced":       false,
+		"oncall_owner":   true,
+		"team":           false,
+		"my_oncall_team": false,
+	}
+	for key, expected := range cases {
+		if result := config.ignored(key); result != expected {
+			t.Fatalf("expected %t for key %s, got %t", expected, key, result)
+		}
+	}
+
+	meta := &providerConfig{
+		ignoreLabels: config,
+	}
+	d := schema.TestResourceDataRaw(t, resourceHealthcheckTCP().Schema, map[string]interface{}{
+		resHealthcheckLabels: map[string]interface{}{
+			"team": "core",
+		},
+	})
+	apiLabels := map[string]string{
+		"team":         "core",
+		"silenced_by":  "alice",
+		"oncall_owner": "bob",
+	}
+	if err := setHealthcheckLabels(d, meta, apiLabels); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"team": "core",
+	}
+	if labels := d.Get(resHealthcheckLabels).(map[string]interface{}); !reflect.DeepEqual(labels, expected) {
+		t.Fatalf("expected labels %v, got %v", expected, labels)
+	}
+	if labels := d.Get(resHealthcheckLabelsAll).(map[string]interface{}); !reflect.DeepEqual(labels, expected) {
+		t.Fatalf("expected labels_all %v, got %v", expected, labels)
+	}
+}
+
+func TestIgnoredLabelsCustomizeDiff(t *testing.T) {
+	meta := &providerConfig{
+		ignoreLabels: ignoreLabelsConfig{
+			keys:        []string{"silenced_by"},
+			keyPrefixes: []string{"oncall_"},
+		},
+	}
+	_, err := testResourceDiff(t, resourceHealthcheckTCP(), map[string]interface{}{
+		resHealthcheckName:   "tf_acc_tcp",
+		resHealthcheckTarget: "appclacks.com",
+		resHealthcheckPort:   443,
+		resHealthcheckLabels: map[string]interface{}{
+			"team":         "core",
+			"silenced_by":  "alice",
+			"oncall_owner": "bob",
+		},
+	}, meta)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, key := range []string{`labels["silenced_by"]`, `labels["oncall_owner"]`} {
+		if !strings.Contains(err.Error(), key+": the label is ignored by the provider ignore_labels") {
+			t.Fatalf("expected an error for %s, got %s", key, err)
+		}
+	}
+	if strings.Contains(err.Error(), `labels["team"]`) {
+		t.Fatalf("unexpected error for team: %s", err)
+	}
+
+	diags := checkIgnoredLabels(meta.ignoreLabels, cty.GetAttrPath(resHealthcheckLabels), map[string]interface{}{"silenced_by": "alice"})
+	if len(diags) != 1 || !diags[0].AttributePath.Equals(cty.GetAttrPath(resHealthcheckLabels).IndexString("silenced_by")) {
+		t.Fatalf("expected a diagnostic on the silenced_by label, got %v", diags)
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	path := cty.GetAttrPath(resHealthcheckLabels)
+	if diags := validateLabels(map[string]interface{}{"team": "core"}, path); diags.HasError() {
+		t.Fatalf("unexpected error %v", diags)
+	}
+	diags := validateLabels(map[string]interface{}{
+		"team":                   "",
+		strings.Repeat("a", 256): "core",
+		"env":                    strings.Repeat("a", 256),
+	}, path)
+	if len(diags) != 3 {
+		t.Fatalf("expected 3 errors, got %v", diags)
+	}
+	if !diags[0].AttributePath.Equals(path.IndexString(strings.Repeat("a", 256))) {
+		t.Fatalf("unexpected attribute path %v", diags[0].AttributePath)
+	}
+	if !strings.Contains(diags[1].Detail, `labels["env"]: label values should be between 1 and 255 characters`) {
+		t.Fatalf("unexpected error %s", diags[1].Detail)
+	}
+	if !diags[2].AttributePath.Equals(path.IndexString("team")) {
+		t.Fatalf("unexpected attribute path %v", diags[2].AttributePath)
+	}
+}
+
+func TestLabelKeyPattern(t *testing.T) {
+	meta := &providerConfig{
+		labelKeyPattern: regexp.MustCompile(`^[a-z][a-z0-9_]*$`),
+	}
+	_, err := testResourceDiff(t, resourceHealthcheckTCP(), map[string]interface{}{
+		resHealthcheckName:   "tf_acc_tcp",
+		resHealthcheckTarget: "appclacks.com",
+		resHealthcheckPort:   443,
+		resHealthcheckLabels: map[string]interface{}{
+			"team": "core",
+		},
+	}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = testResourceDiff(t, resourceHealthcheckTCP(), map[string]interface{}{
+		resHealthcheckName:   "tf_acc_tcp",
+		resHealthcheckTarget: "appclacks.com",
+		resHealthcheckPort:   443,
+		resHealthcheckLabels: map[string]interface{}{
+			"Team":    "core",
+			"env":     "prod",
+			"1-owner": "alice",
+		},
+	}, meta)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, key := range []string{`labels["Team"]`, `labels["1-owner"]`} {
+		if !strings.Contains(err.Error(), key) {
+			t.Fatalf("expected an error for %s, got %s", key, err)
+		}
+	}
+	if strings.Contains(err.Error(), `labels["env"]`) {
+		t.Fatalf("unexpected error for env: %s", err)
+	}
+}
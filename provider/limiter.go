@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
+)
+
+// limiter limits the number of concurrent API calls and the number of
+// API calls per second. It is shared by all the resources of a provider.
+type limiter struct {
+	semaphore chan struct{}
+	rate      *rate.Limiter
+}
+
+func newLimiter(maxConcurrentRequests int, requestsPerSecond float64) *limiter {
+	l := &limiter{}
+	if maxConcurrentRequests > 0 {
+		l.semaphore = make(chan struct{}, maxConcurrentRequests)
+	}
+	if requestsPerSecond > 0 {
+		burst := int(math.Ceil(requestsPerSecond))
+		l.rate = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+	return l
+}
+
+// wait blocks until the request can be sent or the context is done.
+// The returned function should be called once the request is completed.
+func (l *limiter) wait(ctx context.Context, operation string) (func(), error) {
+	release := func() {}
+	if l == nil {
+		return release, nil
+	}
+	start := time.Now()
+	if l.semaphore != nil {
+		select {
+		case l.semaphore <- struct{}{}:
+		case <-ctx.Done():
+			return release, ctx.Err()
+		}
+		release = func() {
+			<-l.semaphore
+		}
+	}
+	if l.rate != nil {
+		if err := l.rate.Wait(ctx); err != nil {
+			release()
+			return func() {}, err
+		}
+	}
+	if waited := time.Since(start); waited >= time.Millisecond {
+		tflog.Debug(ctx, "Waited for the Appclacks API rate limiter", map[string]interface{}{
+			"operation": operation,
+			"wait":      waited.String(),
+		})
+	}
+	return release, nil
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiterConcurrency(t *testing.T) {
+	l := newLimiter(2, 0)
+	var current, max atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.wait(context.Background(), "test")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+			n := current.Add(1)
+			for {
+				m := max.Load()
+				if n <= m || max.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			current.Add(-1)
+		}()
+	}
+	wg.Wait()
+	if max.Load() > 2 {
+		t.Fatalf("expected at most 2 concurrent requests, got %d", max.Load())
+	}
+}
+
+func TestLimiterContextCanceled(t *testing.T) {
+	l := newLimiter(1, 0)
+	release, err := l.wait(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.wait(ctx, "test"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline exceeded error, got %v", err)
+	}
+}
+
+func TestLimiterRate(t *testing.T) {
+	l := newLimiter(0, 20)
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		release, err := l.wait(context.Background(), "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	// the 20 first requests use the burst, the 10 others are spaced by 50ms
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Fatalf("expected the requests to be rate limited, took %s", elapsed)
+	}
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	var l *limiter
+	release, err := l.wait(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+}
@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// logSubsystemAPI is the logging subsystem of the API calls. Its level can be
+	// set independently with the TF_LOG_PROVIDER_APPCLACKS_API environment variable.
+	logSubsystemAPI = "api"
+
+	logEnvPrefix = "TF_LOG_PROVIDER_APPCLACKS"
+	logMask      = "***"
+)
+
+// logMaskedFields are the payload fields whose values are masked in the logs
+var logMaskedFields = map[string]bool{
+	"password": true,
+	"secret":   true,
+	"token":    true,
+	"body":     true,
+}
+
+// logMaskedMapFields are the payload fields whose map values are masked in the logs.
+// The HTTP health check headers often contain credentials.
+var logMaskedMapFields = map[string]bool{
+	"headers": true,
+}
+
+// apiOperation describes an Appclacks API call, for the logs
+type apiOperation struct {
+	Name   string
+	Method string
+	Path   string
+	Body   any
+	// EmptyFields are added to the payload with their empty value when the go-client omits them
+	EmptyFields map[string]any
+}
+
+// apiLogSubsystemKey marks the contexts in which the API subsystem logger is created
+type apiLogSubsystemKey struct{}
+
+// withAPILogSubsystem creates the API subsystem logger, once per resource operation:
+// it is reused by all the API calls of the operation, retries included. The
+// provider credentials are masked from all the messages and fields.
+func (c *Client) withAPILogSubsystem(ctx context.Context) context.Context {
+	if ctx.Value(apiLogSubsystemKey{}) != nil {
+		return ctx
+	}
+	ctx = tflog.NewSubsystem(ctx, logSubsystemAPI, tflog.WithLevelFromEnv(logEnvPrefix, logSubsystemAPI))
+	if len(c.secrets) > 0 {
+		ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, logSubsystemAPI, c.secrets...)
+		ctx = tflog.SubsystemMaskMessageStrings(ctx, logSubsystemAPI, c.secrets...)
+	}
+	return context.WithValue(ctx, apiLogSubsystemKey{}, true)
+}
+
+// apiLogContext returns the context of the API subsystem logger for an API call.
+// The subsystem is created if the caller did not create it.
+func (c *Client) apiLogContext(ctx context.Context, operation apiOperation) context.Context {
+	ctx = c.withAPILogSubsystem(ctx)
+	return tflog.SubsystemSetField(ctx, logSubsystemAPI, "operation", operation.Name)
+}
+
+// logAPIRequest logs an API request. The body is only logged at the TRACE level.
+func logAPIRequest(ctx context.Context, operation apiOperation) {
+	fields := map[string]interface{}{
+		"http_method": operation.Method,
+		"http_path":   operation.Path,
+	}
+	tflog.SubsystemDebug(ctx, logSubsystemAPI, "Sending Appclacks API request", fields)
+	if operation.Body != nil {
+		tflog.SubsystemTrace(ctx, logSubsystemAPI, "Appclacks API request body", map[string]interface{}{
+			"http_method":       operation.Method,
+			"http_path":         operation.Path,
+			"http_request_body": maskLogPayload(operation.Body),
+		})
+	}
+}
+
+// logAPIResponse logs an API response. The go-client only exposes the status code
+// of the error responses, and the decoded body of the successful ones.
+func logAPIResponse(ctx context.Context, operation apiOperation, result any, err error, duration time.Duration) {
+	fields := map[string]interface{}{
+		"http_method": operation.Method,
+		"http_path":   operation.Path,
+		"duration_ms": duration.Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		if apiErr, ok := parseAPIError(err); ok {
+			fields["http_status_code"] = apiErr.StatusCode
+			fields["http_response_body"] = maskLogBody(apiErr.Body)
+		} else if errors.Is(err, goclient.ErrNotFound) {
+			fields["http_status_code"] = 404
+		}
+		tflog.SubsystemDebug(ctx, logSubsystemAPI, "Appclacks API request failed", fields)
+		return
+	}
+	tflog.SubsystemDebug(ctx, logSubsystemAPI, "Received Appclacks API response", fields)
+	fields["http_response_body"] = maskLogPayload(result)
+	tflog.SubsystemTrace(ctx, logSubsystemAPI, "Appclacks API response body", fields)
+}
+
+// maskLogPayload returns the JSON encoding of the payload, with the sensitive values masked
+func maskLogPayload(payload any) string {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return logMask
+	}
+	return maskLogBody(string(content))
+}
+
+// maskLogBody masks the sensitive values of a JSON body. Other bodies are returned as is.
+func maskLogBody(body string) string {
+	var value any
+	if json.Unmarshal([]byte(body), &value) != nil {
+		return body
+	}
+	content, err := json.Marshal(maskPayloadValue(value, logMaskedFields, logMaskedMapFields, logMask))
+	if err != nil {
+		return logMask
+	}
+	return string(content)
+}
+
+// maskPayloadValue replaces the values of the given fields of a decoded JSON payload,
+// and the values of the given map fields, with the mask.
+// It is shared by the logs and the cassettes.
+func maskPayloadValue(value any, fields map[string]bool, mapFields map[string]bool, mask string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			key := strings.ToLower(k)
+			if _, ok := item.(string); ok && fields[key] {
+				v[k] = mask
+				continue
+			}
+			if m, ok := item.(map[string]any); ok && mapFields[key] {
+				for mk := range m {
+					m[mk] = mask
+				}
+				continue
+			}
+			v[k] = maskPayloadValue(item, fields, mapFields, mask)
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = maskPayloadValue(v[i], fields, mapFields, mask)
+		}
+		return v
+	}
+	return value
+}
@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testLogEntries decodes the logs, failing the test if they contain one of the secrets
+func testLogEntries(t *testing.T, output *bytes.Buffer, secrets ...string) []map[string]interface{} {
+	for _, secret := range secrets {
+		if strings.Contains(output.String(), secret) {
+			t.Fatalf("the logs contain the secret %q:\n%s", secret, output.String())
+		}
+	}
+	entries, err := tflogtest.MultilineJSONDecode(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+// testLogEntry returns the first log entry with the given message
+func testLogEntry(t *testing.T, entries []map[string]interface{}, message string) map[string]interface{} {
+	for _, entry := range entries {
+		if entry["@message"] == message {
+			return entry
+		}
+	}
+	t.Fatalf("no log entry %q in %v", message, entries)
+	return nil
+}
+
+func TestClientLogging(t *testing.T) {
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"b6dd6bfc-8a75-11ed-a1eb-0242ac120002","name":"tf_acc_http","type":"http","interval":"60s","timeout":"10s","target":"appclacks.com","port":443,"method":"POST","protocol":"https","valid-status":[200],"headers":{"Authorization":"Bearer s3cr3t-token"},"body":"{\"password\":\"s3cr3t-body\"}"}`)
+	}))
+	client.limiter = newLimiter(0, 0)
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	_, err := client.CreateHTTPHealthcheck(ctx, goclient.CreateHTTPHealthcheckInput{
+		Name:     "tf_acc_http",
+		Interval: "60s",
+		Timeout:  "10s",
+		HealthcheckHTTPDefinition: goclient.HealthcheckHTTPDefinition{
+			Target:      "appclacks.com",
+			Port:        443,
+			Method:      "POST",
+			Protocol:    "https",
+			ValidStatus: []uint{200},
+			Headers:     map[string]string{"Authorization": "Bearer s3cr3t-token"},
+			Body:        `{"password":"s3cr3t-body"}`,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := testLogEntries(t, &output, "s3cr3t-token", "s3cr3t-body")
+	request := testLogEntry(t, entries, "Sending Appclacks API request")
+	if request["@module"] != "provider.api" {
+		t.Fatalf("expected the api subsystem, got %v", request["@module"])
+	}
+	if request["operation"] != "CreateHTTPHealthcheck" || request["http_method"] != "POST" || request["http_path"] != "/api/v1/healthcheck/http" {
+		t.Fatalf("unexpected request entry %v", request)
+	}
+	body := testLogEntry(t, entries, "Appclacks API request body")
+	if requestBody := body["http_request_body"].(string); !strings.Contains(requestBody, `"headers":{"Authorization":"***"}`) || !strings.Contains(requestBody, `"body":"***"`) {
+		t.Fatalf("expected masked headers and body, got %s", requestBody)
+	}
+	response := testLogEntry(t, entries, "Received Appclacks API response")
+	if _, ok := response["duration_ms"]; !ok {
+		t.Fatalf("expected the request duration, got %v", response)
+	}
+	responseBody := testLogEntry(t, entries, "Appclacks API response body")["http_response_body"].(string)
+	if !strings.Contains(responseBody, `"name":"tf_acc_http"`) || !strings.Contains(responseBody, `"headers":{"Authorization":"***"}`) {
+		t.Fatalf("unexpected response body %s", responseBody)
+	}
+}
+
+func TestClientLoggingError(t *testing.T) {
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"messages":["invalid password hunter2-password"]}`)
+	}))
+	client.limiter = newLimiter(0, 0)
+	client.secrets = []string{"hunter2-password"}
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	_, err := client.GetHealthcheck(ctx, goclient.GetHealthcheckInput{Identifier: "tf_acc_tcp"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	entries := testLogEntries(t, &output, "hunter2-password")
+	entry := testLogEntry(t, entries, "Appclacks API request failed")
+	if entry["http_status_code"] != float64(400) || entry["http_method"] != "GET" || entry["http_path"] != "/api/v1/healthcheck/tf_acc_tcp" {
+		t.Fatalf("unexpected error entry %v", entry)
+	}
+}
+
+func TestClientLoggingSubsystemReused(t *testing.T) {
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"messages":["invalid password hunter2-password"]}`)
+	}))
+	client.limiter = newLimiter(0, 0)
+	client.secrets = []string{"hunter2-password"}
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	read := traceCRUD("appclacks_healthcheck_tcp", "read", func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		// the fields set on the subsystem of the operation are kept by the API calls
+		ctx = tflog.SubsystemSetField(ctx, logSubsystemAPI, "resource_operation", "read")
+		for i := 0; i < 2; i++ {
+			if _, err := GetAppclacksClient(meta).GetHealthcheck(ctx, goclient.GetHealthcheckInput{Identifier: "tf_acc_tcp"}); err == nil {
+				t.Fatal("expected an error")
+			}
+		}
+		return nil
+	})
+	read(ctx, schema.TestResourceDataRaw(t, resourceHealthcheckTCP().Schema, map[string]interface{}{}), &providerConfig{client: client})
+
+	entries := testLogEntries(t, &output, "hunter2-password")
+	failures := 0
+	for _, entry := range entries {
+		if entry["@message"] != "Appclacks API request failed" {
+			continue
+		}
+		failures++
+		if entry["@module"] != "provider.api" || entry["resource_operation"] != "read" || entry["operation"] != "GetHealthcheck" {
+			t.Fatalf("unexpected error entry %v", entry)
+		}
+	}
+	if failures != 2 {
+		t.Fatalf("expected 2 failed requests, got %d", failures)
+	}
+}
+
+func TestMaskLogBody(t *testing.T) {
+	cases := map[string]string{
+		"":           "",
+		"<html>":     "<html>",
+		`{"port":1}`: `{"port":1}`,
+		`{"name":"tf_acc","password":"a","nested":[{"Token":"b"}],"headers":{"X-Api-Key":"c"},"body":"d"}`: `{"body":"***","headers":{"X-Api-Key":"***"},"name":"tf_acc","nested":[{"Token":"***"}],"password":"***"}`,
+	}
+	for body, expected := range cases {
+		if masked := maskLogBody(body); masked != expected {
+			t.Fatalf("expected %s, got %s", expected, masked)
+		}
+	}
+}
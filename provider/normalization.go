@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// durationsEqual returns true if both values are valid durations of the same length (example: 1m and 60s)
+func durationsEqual(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	durationA, err := time.ParseDuration(a)
+	if err != nil {
+		return false
+	}
+	durationB, err := time.ParseDuration(b)
+	if err != nil {
+		return false
+	}
+	return durationA == durationB
+}
+
+// suppressEquivalentDuration suppresses the diff between two spellings of the same duration
+func suppressEquivalentDuration(_, old, new string, _ *schema.ResourceData) bool {
+	return durationsEqual(old, new)
+}
+
+// suppressCaseInsensitive suppresses the diff between values which only differ by their case
+func suppressCaseInsensitive(_, old, new string, _ *schema.ResourceData) bool {
+	return strings.EqualFold(old, new)
+}
+
+// setDuration stores a duration returned by the API. The current value is kept
+// when it is equivalent, so the representation used in the configuration is
+// preserved in the state.
+func setDuration(d *schema.ResourceData, key string, value string) error {
+	if current, ok := d.Get(key).(string); ok && durationsEqual(current, value) {
+		return nil
+	}
+	return d.Set(key, value)
+}
+
+// canonicalHeaders returns the headers with canonical keys (example: Content-Type)
+func canonicalHeaders(headers map[string]string) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(headers))
+	for k, v := range headers {
+		result[http.CanonicalHeaderKey(k)] = v
+	}
+	return result
+}
@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestDurationsEqual(t *testing.T) {
+	cases := []struct {
+		a     string
+		b     string
+		equal bool
+	}{
+		{a: "60s", b: "1m", equal: true},
+		{a: "1m0s", b: "60s", equal: true},
+		{a: "1h30m", b: "90m", equal: true},
+		{a: "30s", b: "1m", equal: false},
+		{a: "", b: "1m", equal: false},
+		{a: "foo", b: "foo", equal: true},
+		{a: "foo", b: "bar", equal: false},
+	}
+	for _, c := range cases {
+		if result := durationsEqual(c.a, c.b); result != c.equal {
+			t.Fatalf("expected %t for %s and %s, got %t", c.equal, c.a, c.b, result)
+		}
+	}
+}
+
+func TestSetDuration(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceHealthcheckTCP().Schema, map[string]interface{}{
+		resHealthcheckInterval: "60s",
+	})
+	if err := setDuration(d, resHealthcheckInterval, "1m0s"); err != nil {
+		t.Fatal(err)
+	}
+	if v := d.Get(resHealthcheckInterval).(string); v != "60s" {
+		t.Fatalf("expected the configured representation to be kept, got %s", v)
+	}
+	if err := setDuration(d, resHealthcheckInterval, "2m0s"); err != nil {
+		t.Fatal(err)
+	}
+	if v := d.Get(resHealthcheckInterval).(string); v != "2m0s" {
+		t.Fatalf("expected 2m0s, got %s", v)
+	}
+}
+
+func TestCanonicalHeaders(t *testing.T) {
+	headers := canonicalHeaders(map[string]string{
+		"content-type":  "application/json",
+		"x-api-version": "2",
+		"Accept":        "*/*",
+	})
+	expected := map[string]string{
+		"Content-Type":  "application/json",
+		"X-Api-Version": "2",
+		"Accept":        "*/*",
+	}
+	if !reflect.DeepEqual(headers, expected) {
+		t.Fatalf("expected %v, got %v", expected, headers)
+	}
+}
+
+func TestValidateHTTPHeaders(t *testing.T) {
+	path := cty.GetAttrPath(resHealthcheckHTTPHeaders)
+	if diags := validateHTTPHeaders(20)(map[string]interface{}{"Content-Type": "application/json"}, path); diags.HasError() {
+		t.Fatalf("unexpected error %v", diags)
+	}
+	diags := validateHTTPHeaders(20)(map[string]interface{}{
+		"Content-Type": "application/json",
+		"content-type": "text/plain",
+	}, path)
+	if !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(diags[0].Detail, `headers["content-type"]: the header is already defined as "Content-Type"`) {
+		t.Fatalf("unexpected error %s", diags[0].Detail)
+	}
+}
+
+func TestHealthcheckHTTPNormalization(t *testing.T) {
+	r := resourceHealthcheckHTTP()
+	d := r.TestResourceData()
+	d.SetId("b6dd6bfc-8a75-11ed-a1eb-0242ac120002")
+	err := resourceHTTPHealthcheckApply(context.Background(), d, &providerConfig{}, &goclient.Healthcheck{
+		ID:       "b6dd6bfc-8a75-11ed-a1eb-0242ac120002",
+		Name:     "tf_acc_http",
+		Type:     "http",
+		Interval: "1m0s",
+		Timeout:  "5s",
+		Enabled:  true,
+		Definition: goclient.HealthcheckHTTPDefinition{
+			Target:      "appclacks.com",
+			Port:        443,
+			Method:      "GET",
+			Protocol:    "https",
+			ValidStatus: []uint{200},
+			Headers: map[string]string{
+				"content-type": "application/json",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if headers := d.Get(resHealthcheckHTTPHeaders).(map[string]interface{}); headers["Content-Type"] != "application/json" {
+		t.Fatalf("expected canonical header keys, got %v", headers)
+	}
+
+	config := testRawConfig(t, r, map[string]interface{}{
+		resHealthcheckName:            "tf_acc_http",
+		resHealthcheckTarget:          "appclacks.com",
+		resHealthcheckPort:            443,
+		resHealthcheckInterval:        "60s",
+		resHealthcheckTimeout:         "5000ms",
+		resHealthcheckHTTPMethod:      "get",
+		resHealthcheckHTTPValidStatus: []int{200},
+		resHealthcheckHTTPHeaders: map[string]string{
+			"Content-Type": "application/json",
+		},
+	})
+	state := d.State()
+	state.RawConfig = config
+	meta := &providerConfig{
+		healthcheckDefaults: expandHealthcheckDefaults(nil),
+	}
+	diff, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigShimmed(config, r.CoreConfigSchema()), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != nil && len(diff.Attributes) != 0 {
+		t.Fatalf("expected an empty plan, got %v", diff.Attributes)
+	}
+}
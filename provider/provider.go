@@ -2,21 +2,28 @@ package provider
 
 import (
 	"context"
+	"os"
+	"regexp"
 	"time"
 
 	"github.com/appclacks/go-client"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const (
-	defaultTimeout             = 10 * time.Second
+	// defaultTimeout bounds the resource operations, retries included. It is longer than
+	// the waits between the retries with the default max_retries and retry_max_wait:
+	// the resources reject the timeouts shorter than the waits of the provider settings.
+	defaultTimeout             = 2 * time.Minute
 	defaultHealthcheckTimeout  = "10s"
 	defaultHealthcheckInterval = "60s"
 )
 
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		ConfigureContextFunc: providerConfigure,
 		Schema: map[string]*schema.Schema{
 			"api_endpoint": {
@@ -47,9 +54,66 @@ func Provider() *schema.Provider {
 				Type:     schema.TypeBool,
 				Optional: true,
 			},
+			"default_labels": {
+				Type:             schema.TypeMap,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				Optional:         true,
+				ValidateDiagFunc: validateLabels,
+			},
+			"label_key_pattern": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+			},
+			"request_timeout": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateDuration,
+			},
+			"max_retries": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  defaultMaxRetries,
+			},
+			"retry_max_wait": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateDuration,
+				Default:          defaultRetryMaxWait,
+			},
+			"max_concurrent_requests": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"requests_per_second": {
+				Type:     schema.TypeFloat,
+				Optional: true,
+			},
+			providerHealthcheckDefaults: healthcheckDefaultsSchema(),
+			"ignore_labels": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"keys": {
+							Type:     schema.TypeSet,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Optional: true,
+						},
+						"key_prefixes": {
+							Type:     schema.TypeSet,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Optional: true,
+						},
+					},
+				},
+			},
 		},
 
-		DataSourcesMap: map[string]*schema.Resource{},
+		DataSourcesMap: map[string]*schema.Resource{
+			"appclacks_alertmanager_routes": dataSourceAlertmanagerRoutes(),
+		},
 		ResourcesMap: map[string]*schema.Resource{
 			"appclacks_healthcheck_dns":     resourceHealthcheckDNS(),
 			"appclacks_healthcheck_tcp":     resourceHealthcheckTCP(),
@@ -58,6 +122,8 @@ func Provider() *schema.Provider {
 			"appclacks_healthcheck_command": resourceHealthcheckCommand(),
 		},
 	}
+	traceResources(p)
+	return p
 }
 
 // providerConfigure parses the config into the Terraform provider meta object
@@ -71,35 +137,125 @@ func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{},
 	if ok {
 		options = append(options, client.WithUsername(configUsername.(string)))
 	}
+	var secrets []string
+	if password := os.Getenv("APPCLACKS_PASSWORD"); password != "" {
+		secrets = append(secrets, password)
+	}
 	configPassword, ok := d.GetOk("password")
 	if ok {
 		options = append(options, client.WithPassword(configPassword.(string)))
+		secrets = append(secrets, configPassword.(string))
 	}
-	configTLSKey, ok := d.GetOk("tls_key")
-	if ok {
-		options = append(options, client.WithKey(configTLSKey.(string)))
-	}
-	configTLSCert, ok := d.GetOk("tls_cert")
-	if ok {
-		options = append(options, client.WithCert(configTLSCert.(string)))
-	}
-	configTLSCacert, ok := d.GetOk("tls_cacert")
-	if ok {
-		options = append(options, client.WithCacert(configTLSCacert.(string)))
+	// the TLS settings are applied by the provider transport, and cleared from the
+	// go-client (which reads them from the environment) so it uses http.DefaultTransport
+	endpointConfig := apiEndpointFromConfig(d)
+	transport, err := newAPITransport(endpointConfig)
+	if err != nil {
+		return nil, diag.FromErr(err)
 	}
-	configTLSInsecure, ok := d.GetOk("insecure")
-	if ok {
-		options = append(options, client.WithInsecure(configTLSInsecure.(bool)))
+	options = append(options,
+		client.WithKey(""),
+		client.WithCert(""),
+		client.WithCacert(""),
+		client.WithInsecure(false),
+	)
+	if mode := os.Getenv(envCassetteMode); mode != "" {
+		endpoint, err := startCassetteServer(mode, os.Getenv(envCassettePath), endpointConfig)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		options = append(options, client.WithEndpoint(endpoint))
 	}
-	client, err := client.New(options...)
+	appclacksClient, err := client.New(options...)
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
 
-	return client, nil
+	retryMaxWait, err := time.ParseDuration(d.Get("retry_max_wait").(string))
+	if err != nil {
+		return nil, diag.Errorf("invalid retry_max_wait: %s", err)
+	}
+	maxRetries := d.Get("max_retries").(int)
+	if maxRetries < 0 {
+		return nil, diag.Errorf("max_retries should be positive, got %d", maxRetries)
+	}
+
+	var requestTimeout time.Duration
+	if v, ok := d.GetOk("request_timeout"); ok {
+		requestTimeout, err = time.ParseDuration(v.(string))
+		if err != nil {
+			return nil, diag.Errorf("invalid request_timeout: %s", err)
+		}
+	}
+
+	maxConcurrentRequests := d.Get("max_concurrent_requests").(int)
+	if maxConcurrentRequests < 0 {
+		return nil, diag.Errorf("max_concurrent_requests should be positive, got %d", maxConcurrentRequests)
+	}
+	requestsPerSecond := d.Get("requests_per_second").(float64)
+	if requestsPerSecond < 0 {
+		return nil, diag.Errorf("requests_per_second should be positive, got %f", requestsPerSecond)
+	}
+
+	config := &providerConfig{
+		client: &Client{
+			client:           appclacksClient,
+			transport:        transport,
+			limiter:          newLimiter(maxConcurrentRequests, requestsPerSecond),
+			requestTimeout:   requestTimeout,
+			maxRetries:       maxRetries,
+			retryMinWait:     defaultRetryMinWait,
+			retryMaxWait:     retryMaxWait,
+			secrets:          secrets,
+			credentialSource: credentialSource(d),
+		},
+		defaultLabels:       make(map[string]string),
+		healthcheckDefaults: expandHealthcheckDefaults(d),
+	}
+	if v, ok := d.GetOk("label_key_pattern"); ok {
+		config.labelKeyPattern, err = regexp.Compile(v.(string))
+		if err != nil {
+			return nil, diag.Errorf("invalid label_key_pattern: %s", err)
+		}
+	}
+	if l, ok := d.GetOk("default_labels"); ok {
+		labels := l.(map[string]interface{})
+		if diags := checkLabelKeys(config.labelKeyPattern, cty.GetAttrPath("default_labels"), labels); diags.HasError() {
+			return nil, diags
+		}
+		for k, v := range labels {
+			config.defaultLabels[k] = v.(string)
+		}
+	}
+
+	if l, ok := d.GetOk("ignore_labels"); ok {
+		if ignore, ok := l.([]interface{})[0].(map[string]interface{}); ok {
+			for _, v := range ignore["keys"].(*schema.Set).List() {
+				config.ignoreLabels.keys = append(config.ignoreLabels.keys, v.(string))
+			}
+			for _, v := range ignore["key_prefixes"].(*schema.Set).List() {
+				config.ignoreLabels.keyPrefixes = append(config.ignoreLabels.keyPrefixes, v.(string))
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// providerConfig is the provider meta object passed to the resources
+type providerConfig struct {
+	client              *Client
+	defaultLabels       map[string]string
+	labelKeyPattern     *regexp.Regexp
+	ignoreLabels        ignoreLabelsConfig
+	healthcheckDefaults map[string]healthcheckDefaults
+}
+
+func getProviderConfig(meta interface{}) *providerConfig {
+	config := meta.(*providerConfig)
+	return config
 }
 
-func GetAppclacksClient(meta interface{}) *client.Client {
-	client := meta.(*client.Client)
-	return client
+func GetAppclacksClient(meta interface{}) *Client {
+	return getProviderConfig(meta).client
 }
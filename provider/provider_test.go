@@ -8,6 +8,7 @@ import (
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
@@ -29,11 +30,23 @@ func init() {
 	}
 }
 
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testAccPreCheck runs the acceptance tests against the API configured with
+// APPCLACKS_API_ENDPOINT, or against a fake API when no endpoint is configured
 func testAccPreCheck(t *testing.T) {
 	endpoint := os.Getenv("APPCLACKS_API_ENDPOINT")
 	if endpoint == "" {
-		msg := "APPCLACKS_API_ENDPOINT must be set to launch tests"
-		t.Fatal(msg)
+		_, server := newFakeAPI(t)
+		t.Setenv("APPCLACKS_API_ENDPOINT", server.URL)
 	}
 }
 
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-cty/cty"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// rawConfigValidator checks the raw configuration of a resource. Unlike the
+// ValidateDiagFunc of the attributes, it sees all the attributes, and unlike
+// CustomizeDiff, it can return warnings.
+type rawConfigValidator func(config cty.Value) diag.Diagnostics
+
+// resourceRawConfigValidators are the raw configuration validators of the resources
+var resourceRawConfigValidators = map[string]rawConfigValidator{
+	"appclacks_healthcheck_http": func(config cty.Value) diag.Diagnostics {
+		return healthcheckTLSConfigWarnings("http", config)
+	},
+	"appclacks_healthcheck_tls": func(config cty.Value) diag.Diagnostics {
+		return healthcheckTLSConfigWarnings("tls", config)
+	},
+}
+
+// providerServer is the gRPC server of the provider. It runs the SDK provider,
+// and adds the diagnostics of the resourceRawConfigValidators to the resource
+// validation, so terraform validate, plan and apply show them.
+type providerServer struct {
+	tfprotov5.ProviderServer
+	provider *schema.Provider
+}
+
+// ProviderServer returns the gRPC server of the provider
+func ProviderServer() tfprotov5.ProviderServer {
+	p := Provider()
+	return &providerServer{
+		ProviderServer: schema.NewGRPCProviderServer(p),
+		provider:       p,
+	}
+}
+
+func (s *providerServer) ValidateResourceTypeConfig(ctx context.Context, req *tfprotov5.ValidateResourceTypeConfigRequest) (*tfprotov5.ValidateResourceTypeConfigResponse, error) {
+	resp, err := s.ProviderServer.ValidateResourceTypeConfig(ctx, req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	validate, ok := resourceRawConfigValidators[req.TypeName]
+	r, found := s.provider.ResourcesMap[req.TypeName]
+	if !ok || !found || req.Config == nil {
+		return resp, nil
+	}
+	config, err := decodeDynamicValue(req.Config, r.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		// the SDK already reported the configurations which cannot be decoded
+		return resp, nil //nolint:nilerr
+	}
+	for _, diagnostic := range validate(config) {
+		resp.Diagnostics = append(resp.Diagnostics, protoDiagnostic(diagnostic))
+	}
+	return resp, nil
+}
+
+// decodeDynamicValue decodes a value sent by Terraform, encoded in MessagePack or JSON
+func decodeDynamicValue(value *tfprotov5.DynamicValue, ty cty.Type) (cty.Value, error) {
+	switch {
+	case len(value.MsgPack) > 0:
+		return msgpack.Unmarshal(value.MsgPack, ty)
+	case len(value.JSON) > 0:
+		return ctyjson.Unmarshal(value.JSON, ty)
+	}
+	return cty.NullVal(ty), nil
+}
+
+// protoDiagnostic converts a diagnostic to its protocol representation
+func protoDiagnostic(diagnostic diag.Diagnostic) *tfprotov5.Diagnostic {
+	result := &tfprotov5.Diagnostic{
+		Severity:  tfprotov5.DiagnosticSeverityError,
+		Summary:   diagnostic.Summary,
+		Detail:    diagnostic.Detail,
+		Attribute: protoAttributePath(diagnostic.AttributePath),
+	}
+	if diagnostic.Severity == diag.Warning {
+		result.Severity = tfprotov5.DiagnosticSeverityWarning
+	}
+	return result
+}
+
+// protoAttributePath converts an attribute path to its protocol representation.
+// The conversion stops at the first step without a protocol equivalent.
+func protoAttributePath(path cty.Path) *tftypes.AttributePath {
+	if len(path) == 0 {
+		return nil
+	}
+	steps := make([]tftypes.AttributePathStep, 0, len(path))
+	for _, step := range path {
+		switch s := step.(type) {
+		case cty.GetAttrStep:
+			steps = append(steps, tftypes.AttributeName(s.Name))
+		case cty.IndexStep:
+			switch s.Key.Type() {
+			case cty.String:
+				steps = append(steps, tftypes.ElementKeyString(s.Key.AsString()))
+			case cty.Number:
+				index, _ := s.Key.AsBigFloat().Int64()
+				steps = append(steps, tftypes.ElementKeyInt(index))
+			default:
+				return tftypes.NewAttributePathWithSteps(steps)
+			}
+		default:
+			return tftypes.NewAttributePathWithSteps(steps)
+		}
+	}
+	return tftypes.NewAttributePathWithSteps(steps)
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestProviderServerValidateResourceTypeConfig(t *testing.T) {
+	cases := []struct {
+		name     string
+		typeName string
+		config   map[string]interface{}
+		warnings []string
+	}{
+		{
+			name:     "http health check with TLS settings",
+			typeName: "appclacks_healthcheck_http",
+			config: map[string]interface{}{
+				resHealthcheckHTTPProtocol:  "http",
+				resHealthcheckTLSServerName: "appclacks.com",
+			},
+			warnings: []string{resHealthcheckTLSServerName},
+		},
+		{
+			name:     "tls health check with an ignored expiration delay",
+			typeName: "appclacks_healthcheck_tls",
+			config: map[string]interface{}{
+				resHealthcheckTLSExpirationDelay: "168h",
+				resHealthcheckTLSInsecure:        true,
+			},
+			warnings: []string{resHealthcheckTLSExpirationDelay},
+		},
+		{
+			name:     "https health check",
+			typeName: "appclacks_healthcheck_http",
+			config: map[string]interface{}{
+				resHealthcheckTLSServerName: "appclacks.com",
+			},
+		},
+		{
+			name:     "tcp health check",
+			typeName: "appclacks_healthcheck_tcp",
+		},
+	}
+	server := ProviderServer()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				resHealthcheckName:   "tf_acc",
+				resHealthcheckTarget: "appclacks.com",
+				resHealthcheckPort:   443,
+			}
+			for k, v := range c.config {
+				config[k] = v
+			}
+			if c.typeName == "appclacks_healthcheck_http" {
+				config[resHealthcheckHTTPValidStatus] = []int{200}
+			}
+			r := testAccProvider.ResourcesMap[c.typeName]
+			value, err := msgpack.Marshal(testRawConfig(t, r, config), r.CoreConfigSchema().ImpliedType())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := server.ValidateResourceTypeConfig(context.Background(), &tfprotov5.ValidateResourceTypeConfigRequest{
+				TypeName: c.typeName,
+				Config:   &tfprotov5.DynamicValue{MsgPack: value},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Diagnostics) != len(c.warnings) {
+				t.Fatalf("expected warnings for %v, got %v", c.warnings, resp.Diagnostics)
+			}
+			for i, warning := range c.warnings {
+				diagnostic := resp.Diagnostics[i]
+				if diagnostic.Severity != tfprotov5.DiagnosticSeverityWarning || diagnostic.Summary != "Ignored TLS setting" {
+					t.Fatalf("expected an ignored TLS setting warning, got %v", diagnostic)
+				}
+				if !diagnostic.Attribute.Equal(tftypes.NewAttributePath().WithAttributeName(warning)) {
+					t.Fatalf("expected a warning on %s, got %v", warning, diagnostic.Attribute)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"testing"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// testSweepNameRegexp matches the names of the resources created by the acceptance tests
+var testSweepNameRegexp = regexp.MustCompile(`^tf_acc_`)
+
+func init() {
+	for _, healthcheckType := range []string{"command", "dns", "http", "tcp", "tls"} {
+		name := fmt.Sprintf("appclacks_healthcheck_%s", healthcheckType)
+		resource.AddTestSweepers(name, &resource.Sweeper{
+			Name: name,
+			F:    testSweepHealthchecks(healthcheckType),
+		})
+	}
+	resource.AddTestSweepers("appclacks_pushgateway_metric", &resource.Sweeper{
+		Name: "appclacks_pushgateway_metric",
+		F:    testSweepPushgatewayMetrics,
+	})
+}
+
+// testSweepClient returns a client configured from the environment variables, like the provider
+func testSweepClient() (*Client, error) {
+	p := Provider()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		return nil, fmt.Errorf("failed to configure the provider: %v", diags)
+	}
+	return GetAppclacksClient(p.Meta()), nil
+}
+
+// testSweepHealthchecks deletes the health checks of the given type left behind by the acceptance tests.
+// The go-client does not support the name pattern filter, so the health checks are filtered here.
+func testSweepHealthchecks(healthcheckType string) func(string) error {
+	return func(_ string) error {
+		client, err := testSweepClient()
+		if err != nil {
+			return err
+		}
+		ctx := context.Background()
+		healthchecks, err := client.ListHealthchecks(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list health checks: %w", err)
+		}
+		var errs []error
+		for _, healthcheck := range healthchecks.Result {
+			if healthcheck.Type != healthcheckType || !testSweepNameRegexp.MatchString(healthcheck.Name) {
+				continue
+			}
+			log.Printf("[INFO] Deleting %s health check %s (%s)", healthcheck.Type, healthcheck.Name, healthcheck.ID)
+			_, err := client.DeleteHealthcheck(ctx, goclient.DeleteHealthcheckInput{ID: healthcheck.ID})
+			if err != nil && !errors.Is(err, goclient.ErrNotFound) {
+				errs = append(errs, fmt.Errorf("failed to delete health check %s: %w", healthcheck.Name, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// testSweepPushgatewayMetrics deletes the pushgateway metrics left behind by the acceptance tests
+func testSweepPushgatewayMetrics(_ string) error {
+	client, err := testSweepClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	metrics, err := client.ListPushgatewayMetrics(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list pushgateway metrics: %w", err)
+	}
+	var errs []error
+	for _, metric := range metrics.Result {
+		if !testSweepNameRegexp.MatchString(metric.Name) {
+			continue
+		}
+		log.Printf("[INFO] Deleting pushgateway metric %s (%s)", metric.Name, metric.ID)
+		if _, err := client.DeletePushgatewayMetric(ctx, goclient.DeletePushgatewayMetricInput{Identifier: metric.ID}); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete pushgateway metric %s: %w", metric.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func TestSweepers(t *testing.T) {
+	api, server := newFakeAPI(t)
+	t.Setenv("APPCLACKS_API_ENDPOINT", server.URL)
+	client, err := testSweepClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, name := range []string{"tf_acc_tcp", "tf_acc_tcp2", "prod_tcp"} {
+		_, err := client.CreateTCPHealthcheck(ctx, goclient.CreateTCPHealthcheckInput{
+			Name:     name,
+			Interval: "60s",
+			Timeout:  "10s",
+			HealthcheckTCPDefinition: goclient.HealthcheckTCPDefinition{
+				Target: "appclacks.com",
+				Port:   443,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = client.CreateDNSHealthcheck(ctx, goclient.CreateDNSHealthcheckInput{
+		Name:     "tf_acc_dns",
+		Interval: "60s",
+		Timeout:  "10s",
+		HealthcheckDNSDefinition: goclient.HealthcheckDNSDefinition{
+			Domain: "appclacks.com",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"tf_acc_metric", "prod_metric"} {
+		_, err := client.CreateOrUpdatePushgatewayMetric(ctx, goclient.CreateOrUpdatePushgatewayMetricInput{
+			Name:  name,
+			Value: "1",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := testSweepHealthchecks("tcp")(""); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, healthcheck := range api.sortedHealthchecks() {
+		names = append(names, healthcheck.Name)
+	}
+	if expected := []string{"prod_tcp", "tf_acc_dns"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected the health checks %v, got %v", expected, names)
+	}
+
+	if err := testSweepPushgatewayMetrics(""); err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := client.ListPushgatewayMetrics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics.Result) != 1 || metrics.Result[0].Name != "prod_metric" {
+		t.Fatalf("expected only the prod_metric metric, got %+v", metrics.Result)
+	}
+}
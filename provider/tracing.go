@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName         = "github.com/appclacks/terraform-provider-appclacks/provider"
+	tracingServiceName = "terraform-provider-appclacks"
+)
+
+// tracer returns the tracer of the provider. Spans are dropped unless ConfigureTracing enabled the tracing.
+func tracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(tracerName)
+}
+
+// ConfigureTracing exports the traces of the provider with OTLP when the
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment
+// variable is set. The exporter is configured with the standard OTEL_EXPORTER_OTLP_*
+// environment variables. The returned function flushes and stops the exporter.
+func ConfigureTracing(ctx context.Context) (func(context.Context) error, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	var exporter *otlptrace.Exporter
+	var err error
+	switch protocol {
+	case "", "http/protobuf":
+		exporter, err = otlptracehttp.New(ctx)
+	case "grpc":
+		exporter, err = otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q, should be http/protobuf or grpc", protocol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP trace exporter: %w", err)
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the default service name
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", tracingServiceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the tracing resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// traceResources wraps the CRUD functions of the resources and data sources of
+// the provider in spans. The API calls made by these functions are child spans.
+func traceResources(p *schema.Provider) {
+	for name, r := range p.ResourcesMap {
+		traceResource(name, r)
+	}
+	for name, r := range p.DataSourcesMap {
+		traceResource(name, r)
+	}
+}
+
+func traceResource(resourceType string, r *schema.Resource) {
+	if r.CreateContext != nil {
+		r.CreateContext = traceCRUD(resourceType, "create", r.CreateContext)
+	}
+	if r.ReadContext != nil {
+		r.ReadContext = traceCRUD(resourceType, "read", r.ReadContext)
+	}
+	if r.UpdateContext != nil {
+		r.UpdateContext = traceCRUD(resourceType, "update", r.UpdateContext)
+	}
+	if r.DeleteContext != nil {
+		r.DeleteContext = traceCRUD(resourceType, "delete", r.DeleteContext)
+	}
+}
+
+// traceCRUD runs a CRUD function in a span named after the resource type and the operation
+// (example: appclacks_healthcheck_tcp.create). The error diagnostics are recorded as span events.
+// The API subsystem logger is created here, once for all the API calls of the operation.
+func traceCRUD(resourceType string, operation string, f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		attributes := []attribute.KeyValue{
+			attribute.String("appclacks.resource.type", resourceType),
+			attribute.String("appclacks.operation", operation),
+		}
+		if name, ok := d.Get(resHealthcheckName).(string); ok && name != "" {
+			attributes = append(attributes, attribute.String("appclacks.resource.name", name))
+		}
+		ctx, span := tracer().Start(ctx, fmt.Sprintf("%s.%s", resourceType, operation), trace.WithAttributes(attributes...))
+		defer span.End()
+
+		if config, ok := meta.(*providerConfig); ok && config.client != nil {
+			ctx = config.client.withAPILogSubsystem(ctx)
+		}
+		diags := f(ctx, d, meta)
+
+		if d.Id() != "" {
+			span.SetAttributes(attribute.String("appclacks.resource.id", d.Id()))
+		}
+		for _, diagnostic := range diags {
+			if diagnostic.Severity == diag.Error {
+				span.RecordError(errors.New(diagnostic.Summary), trace.WithAttributes(attribute.String("detail", diagnostic.Detail)))
+			}
+		}
+		if diags.HasError() {
+			span.SetStatus(codes.Error, diags[0].Summary)
+		}
+		return diags
+	}
+}
+
+// startAPISpan starts the span of an API call, child of the span of the CRUD operation
+func startAPISpan(ctx context.Context, operation apiOperation) (context.Context, trace.Span) {
+	return tracer().Start(ctx, fmt.Sprintf("appclacks.api %s", operation.Name),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("appclacks.api.operation", operation.Name),
+			attribute.String("http.request.method", operation.Method),
+			attribute.String("url.path", operation.Path),
+		),
+	)
+}
+
+// endAPISpan records the outcome of an API call and ends its span
+func endAPISpan(span trace.Span, err error) {
+	defer span.End()
+	if err == nil {
+		return
+	}
+	if apiErr, ok := parseAPIError(err); ok {
+		span.SetAttributes(attribute.Int("http.response.status_code", apiErr.StatusCode))
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goclient "github.com/appclacks/go-client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.opentelemetry.io/otel"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// testCollector is an in-process stand-in for an OTLP/HTTP collector
+type testCollector struct {
+	lock  sync.Mutex
+	spans []*tracepb.Span
+}
+
+// newTestCollector starts a collector and exports the provider traces to it
+// until the end of the test
+func newTestCollector(t *testing.T) (*testCollector, func()) {
+	collector := &testCollector{}
+	server := httptest.NewServer(collector)
+	t.Cleanup(server.Close)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	shutdown, err := ConfigureTracing(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return collector, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func (c *testCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var request coltracepb.ExportTraceServiceRequest
+	if err := proto.Unmarshal(body, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.lock.Lock()
+	for _, resourceSpans := range request.ResourceSpans {
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			c.spans = append(c.spans, scopeSpans.Spans...)
+		}
+	}
+	c.lock.Unlock()
+	response, err := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(response)
+}
+
+// spansNamed returns the spans with the given name, in their start order
+func (c *testCollector) spansNamed(name string) []*tracepb.Span {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var spans []*tracepb.Span
+	for _, span := range c.spans {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].StartTimeUnixNano < spans[j].StartTimeUnixNano
+	})
+	return spans
+}
+
+func testSpanAttribute(span *tracepb.Span, key string) *commonpb.AnyValue {
+	for _, attribute := range span.Attributes {
+		if attribute.Key == key {
+			return attribute.Value
+		}
+	}
+	return nil
+}
+
+func testSpanEvents(span *tracepb.Span, name string) []*tracepb.Span_Event {
+	var events []*tracepb.Span_Event
+	for _, event := range span.Events {
+		if event.Name == name {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestTracing(t *testing.T) {
+	collector, flush := newTestCollector(t)
+
+	fake, _ := newFakeAPI(t)
+	var failures atomic.Int32
+	failures.Store(1)
+	var failRead atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method == http.MethodGet && failRead.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	c, err := goclient.New(goclient.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := &providerConfig{client: &Client{
+		client:       c,
+		limiter:      newLimiter(0, 0),
+		maxRetries:   3,
+		retryMinWait: time.Millisecond,
+		retryMaxWait: 10 * time.Millisecond,
+	}}
+
+	r := Provider().ResourcesMap["appclacks_healthcheck_tcp"]
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		resHealthcheckName:     "tf_acc_tracing",
+		resHealthcheckInterval: "60s",
+		resHealthcheckTimeout:  "10s",
+		"target":               "appclacks.com",
+		"port":                 443,
+	})
+	ctx := context.Background()
+	if diags := r.CreateContext(ctx, d, meta); diags.HasError() {
+		t.Fatalf("unexpected error %v", diags)
+	}
+	failRead.Store(true)
+	if diags := r.ReadContext(ctx, d, meta); !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+	flush()
+
+	create := collector.spansNamed("appclacks_healthcheck_tcp.create")
+	if len(create) != 1 {
+		t.Fatalf("expected one create span, got %d", len(create))
+	}
+	createSpan := create[0]
+	for key, expected := range map[string]string{
+		"appclacks.resource.type": "appclacks_healthcheck_tcp",
+		"appclacks.resource.name": "tf_acc_tracing",
+		"appclacks.resource.id":   d.Id(),
+		"appclacks.operation":     "create",
+	} {
+		if value := testSpanAttribute(createSpan, key); value.GetStringValue() != expected {
+			t.Fatalf("expected the attribute %s=%s, got %v", key, expected, value)
+		}
+	}
+	if retries := testSpanEvents(createSpan, "retry"); len(retries) != 1 {
+		t.Fatalf("expected one retry event, got %v", createSpan.Events)
+	}
+
+	calls := collector.spansNamed("appclacks.api CreateTCPHealthcheck")
+	if len(calls) != 2 {
+		t.Fatalf("expected two create API calls, got %d", len(calls))
+	}
+	for _, call := range calls {
+		if hex.EncodeToString(call.ParentSpanId) != hex.EncodeToString(createSpan.SpanId) {
+			t.Fatal("expected the API call span to be a child of the create span")
+		}
+		if call.Kind != tracepb.Span_SPAN_KIND_CLIENT {
+			t.Fatalf("expected a client span, got %v", call.Kind)
+		}
+		if method := testSpanAttribute(call, "http.request.method").GetStringValue(); method != http.MethodPost {
+			t.Fatalf("expected the POST method, got %s", method)
+		}
+	}
+	if status := testSpanAttribute(calls[0], "http.response.status_code").GetIntValue(); status != 503 {
+		t.Fatalf("expected the status code 503, got %d", status)
+	}
+	if calls[0].Status.Code != tracepb.Status_STATUS_CODE_ERROR || len(testSpanEvents(calls[0], "exception")) != 1 {
+		t.Fatal("expected the failed API call to be recorded as an error")
+	}
+	if calls[1].Status.Code == tracepb.Status_STATUS_CODE_ERROR {
+		t.Fatal("expected the second API call to succeed")
+	}
+	gets := collector.spansNamed("appclacks.api GetHealthcheck")
+	if len(gets) != 2 || hex.EncodeToString(gets[0].ParentSpanId) != hex.EncodeToString(createSpan.SpanId) {
+		t.Fatalf("expected the create to read the health check, got %d get spans", len(gets))
+	}
+	if path := testSpanAttribute(gets[0], "url.path").GetStringValue(); path != "/api/v1/healthcheck/"+d.Id() {
+		t.Fatalf("unexpected path %s", path)
+	}
+
+	read := collector.spansNamed("appclacks_healthcheck_tcp.read")
+	if len(read) != 1 {
+		t.Fatalf("expected one read span, got %d", len(read))
+	}
+	if read[0].Status.Code != tracepb.Status_STATUS_CODE_ERROR {
+		t.Fatal("expected the read span to be in error")
+	}
+	exceptions := testSpanEvents(read[0], "exception")
+	if len(exceptions) != 1 {
+		t.Fatalf("expected one exception event, got %v", read[0].Events)
+	}
+	for _, attribute := range exceptions[0].Attributes {
+		if attribute.Key == "exception.message" && !strings.Contains(attribute.Value.GetStringValue(), "status 500") {
+			t.Fatalf("unexpected exception %s", attribute.Value.GetStringValue())
+		}
+	}
+}
+
+func TestConfigureTracingDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	previous := otel.GetTracerProvider()
+	shutdown, err := ConfigureTracing(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() != previous {
+		t.Fatal("expected the tracer provider to be unchanged")
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigureTracingInvalidProtocol(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+	_, err := ConfigureTracing(context.Background())
+	if err == nil || err.Error() != `unsupported OTLP protocol "http/json", should be http/protobuf or grpc` {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// apiEndpointConfig is the API endpoint and the TLS settings used to reach it
+type apiEndpointConfig struct {
+	endpoint string
+	key      string
+	cert     string
+	cacert   string
+	insecure bool
+}
+
+// apiEndpointFromConfig returns the API configured on the provider, using the
+// same environment variables as the Appclacks client when an option is not set
+func apiEndpointFromConfig(d *schema.ResourceData) apiEndpointConfig {
+	config := apiEndpointConfig{
+		endpoint: os.Getenv("APPCLACKS_API_ENDPOINT"),
+		key:      os.Getenv("APPCLACKS_TLS_KEY"),
+		cert:     os.Getenv("APPCLACKS_TLS_CERT"),
+		cacert:   os.Getenv("APPCLACKS_TLS_CACERT"),
+		insecure: os.Getenv("APPCLACKS_TLS_INSECURE") == "true",
+	}
+	if v, ok := d.GetOk("api_endpoint"); ok {
+		config.endpoint = v.(string)
+	}
+	if v, ok := d.GetOk("tls_key"); ok {
+		config.key = v.(string)
+	}
+	if v, ok := d.GetOk("tls_cert"); ok {
+		config.cert = v.(string)
+	}
+	if v, ok := d.GetOk("tls_cacert"); ok {
+		config.cacert = v.(string)
+	}
+	if v, ok := d.GetOk("insecure"); ok {
+		config.insecure = v.(bool)
+	}
+	return config
+}
+
+// newAPITransport returns a transport sending the requests to the API with its TLS settings
+func newAPITransport(config apiEndpointConfig) (*http.Transport, error) {
+	transport := apiDefaultTransport.Clone()
+	if config.key != "" || config.cert != "" || config.cacert != "" || config.insecure {
+		tlsConfig, err := apiTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport, nil
+}
+
+// apiTLSConfig builds the TLS configuration of the API requests, like the Appclacks client
+func apiTLSConfig(config apiEndpointConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.insecure, //nolint:gosec
+	}
+	if config.key != "" {
+		cert, err := tls.LoadX509KeyPair(config.cert, config.key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certificates: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if config.cacert != "" {
+		caCert, err := os.ReadFile(config.cacert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load ca certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to read ca certificate on %s", config.cacert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+var (
+	// apiDefaultTransport is the original http.DefaultTransport, before installAPITransport replaces it
+	apiDefaultTransport, _ = http.DefaultTransport.(*http.Transport)
+	apiTransportOnce       sync.Once
+)
+
+// apiExchangeKey is the context key of the apiExchange of an API call
+type apiExchangeKey struct{}
+
+// apiExchange carries the transport of an API call, the payload fields the go-client
+// omits, and the response headers the go-client does not expose
+type apiExchange struct {
+	transport   http.RoundTripper
+	emptyFields map[string]any
+	retryAfter  time.Duration
+}
+
+// apiTransport sends the requests of the API calls with the transport of their
+// apiExchange, adds the empty fields of the exchange to their JSON payload, and
+// records the Retry-After header of the responses. Other requests are sent
+// unchanged by the original default transport.
+type apiTransport struct {
+	base http.RoundTripper
+}
+
+// installAPITransport replaces http.DefaultTransport with an apiTransport. The
+// go-client does not expose its HTTP client, but uses http.DefaultTransport when
+// no TLS option is set: the provider applies the TLS settings in its own transport.
+func installAPITransport() {
+	apiTransportOnce.Do(func() {
+		http.DefaultTransport = &apiTransport{base: http.DefaultTransport}
+	})
+}
+
+func (t *apiTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	exchange, ok := r.Context().Value(apiExchangeKey{}).(*apiExchange)
+	if !ok {
+		return t.base.RoundTrip(r)
+	}
+	transport := t.base
+	if exchange.transport != nil {
+		transport = exchange.transport
+	}
+	if len(exchange.emptyFields) > 0 && r.Body != nil {
+		var err error
+		if r, err = withEmptyFields(r, exchange.emptyFields); err != nil {
+			return nil, err
+		}
+	}
+	response, err := transport.RoundTrip(r)
+	if err == nil {
+		exchange.retryAfter = parseRetryAfter(response.Header.Get("Retry-After"), time.Now())
+	}
+	return response, err
+}
+
+// withEmptyFields returns a copy of the request whose JSON object payload contains the
+// given fields, with their empty value when the payload does not set them. The go-client
+// omits the empty optional fields, which the API would keep unchanged.
+func withEmptyFields(r *http.Request, fields map[string]any) (*http.Request, error) {
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(body, &payload); err == nil && payload != nil {
+		for k, v := range fields {
+			if _, ok := payload[k]; ok {
+				continue
+			}
+			if payload[k], err = json.Marshal(v); err != nil {
+				return nil, err
+			}
+		}
+		if body, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+	result := r.Clone(r.Context())
+	result.Body = io.NopCloser(bytes.NewReader(body))
+	result.ContentLength = int64(len(body))
+	result.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return result, nil
+}
+
+// parseRetryAfter returns the duration to wait from a Retry-After header, either
+// a number of seconds or an HTTP date. Invalid values and past dates return 0.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		switch {
+		case seconds <= 0:
+			return 0
+		case seconds > math.MaxInt64/int64(time.Second):
+			return math.MaxInt64
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	date, err := http.ParseTime(value)
+	if err != nil || !date.After(now) {
+		return 0
+	}
+	return date.Sub(now)
+}
@@ -0,0 +1,352 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	maxHostnameLength = 253
+)
+
+var hostnameLabelRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+var domainLabelRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?$`)
+
+// validateDuration checks that the value is a valid positive Go duration (example: 30s)
+func validateDuration(i interface{}, path cty.Path) diag.Diagnostics {
+	v, ok := i.(string)
+	if !ok {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid type",
+			Detail:        "Expected a string",
+			AttributePath: path,
+		}}
+	}
+	duration, err := time.ParseDuration(v)
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid duration",
+			Detail:        fmt.Sprintf("%q is not a valid duration (examples: 30s, 5m, 1h30m): %s", v, err),
+			AttributePath: path,
+		}}
+	}
+	if duration <= 0 {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid duration",
+			Detail:        fmt.Sprintf("The duration should be positive, got %q", v),
+			AttributePath: path,
+		}}
+	}
+	return nil
+}
+
+// isValidHostname checks that the value is a hostname compliant with RFC 1123.
+// The label regexp can be overridden to support DNS names containing underscores.
+func isValidHostname(value string, labelRegexp *regexp.Regexp) error {
+	hostname := strings.TrimSuffix(value, ".")
+	if hostname == "" {
+		return fmt.Errorf("%q is empty", value)
+	}
+	if len(hostname) > maxHostnameLength {
+		return fmt.Errorf("%q is longer than %d characters", value, maxHostnameLength)
+	}
+	for _, label := range strings.Split(hostname, ".") {
+		if !labelRegexp.MatchString(label) {
+			return fmt.Errorf("%q contains an invalid label %q: labels should contain at most 63 letters, digits or hyphens, and should not start or end with a hyphen", value, label)
+		}
+	}
+	return nil
+}
+
+// validateHostnameOrIP checks that the value is an IP address or a valid hostname
+func validateHostnameOrIP(i interface{}, path cty.Path) diag.Diagnostics {
+	v, ok := i.(string)
+	if !ok {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid type",
+			Detail:        "Expected a string",
+			AttributePath: path,
+		}}
+	}
+	if net.ParseIP(v) != nil {
+		return nil
+	}
+	if err := isValidHostname(v, hostnameLabelRegexp); err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid target",
+			Detail:        fmt.Sprintf("The target should be an IP address or a valid hostname: %s", err),
+			AttributePath: path,
+		}}
+	}
+	return nil
+}
+
+// validateDomain checks that the value is a valid domain name
+func validateDomain(i interface{}, path cty.Path) diag.Diagnostics {
+	v, ok := i.(string)
+	if !ok {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid type",
+			Detail:        "Expected a string",
+			AttributePath: path,
+		}}
+	}
+	if err := isValidHostname(v, domainLabelRegexp); err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid domain",
+			Detail:        fmt.Sprintf("The domain is not a valid domain name: %s", err),
+			AttributePath: path,
+		}}
+	}
+	return nil
+}
+
+// validateIPAddress checks that the value is an IPv4 or IPv6 address
+func validateIPAddress(i interface{}, path cty.Path) diag.Diagnostics {
+	v, ok := i.(string)
+	if !ok {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid type",
+			Detail:        "Expected a string",
+			AttributePath: path,
+		}}
+	}
+	if net.ParseIP(v) == nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid IP address",
+			Detail:        fmt.Sprintf("%s: %q is not an IP address", attributePathString(path), v),
+			AttributePath: path,
+		}}
+	}
+	return nil
+}
+
+// attributePathString returns a human readable representation of an attribute path
+// (example: expected_ips[2])
+func attributePathString(path cty.Path) string {
+	var builder strings.Builder
+	for _, step := range path {
+		switch s := step.(type) {
+		case cty.GetAttrStep:
+			if builder.Len() != 0 {
+				builder.WriteString(".")
+			}
+			builder.WriteString(s.Name)
+		case cty.IndexStep:
+			switch s.Key.Type() {
+			case cty.Number:
+				index, _ := s.Key.AsBigFloat().Int64()
+				builder.WriteString(fmt.Sprintf("[%d]", index))
+			case cty.String:
+				builder.WriteString(fmt.Sprintf("[%q]", s.Key.AsString()))
+			}
+		}
+	}
+	return builder.String()
+}
+
+// validateMapMaxItems checks that a map does not contain more than max elements
+func validateMapMaxItems(max int) schema.SchemaValidateDiagFunc {
+	return func(i interface{}, path cty.Path) diag.Diagnostics {
+		m, ok := i.(map[string]interface{})
+		if !ok {
+			return diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Invalid type",
+				Detail:        "Expected a map",
+				AttributePath: path,
+			}}
+		}
+		if len(m) > max {
+			return diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Too many elements",
+				Detail:        fmt.Sprintf("At most %d elements are allowed, got %d", max, len(m)),
+				AttributePath: path,
+			}}
+		}
+		return nil
+	}
+}
+
+// validateHTTPHeaders checks that a map of HTTP headers does not contain more than
+// max elements, and that no header is defined several times with a different case
+func validateHTTPHeaders(max int) schema.SchemaValidateDiagFunc {
+	validateMaxItems := validateMapMaxItems(max)
+	return func(i interface{}, path cty.Path) diag.Diagnostics {
+		if diags := validateMaxItems(i, path); diags.HasError() {
+			return diags
+		}
+		headers := i.(map[string]interface{})
+		keys := make(map[string]string)
+		var diags diag.Diagnostics
+		for _, k := range sortedKeys(headers) {
+			canonical := http.CanonicalHeaderKey(k)
+			if other, ok := keys[canonical]; ok {
+				diags = append(diags, diag.Diagnostic{
+					Severity:      diag.Error,
+					Summary:       "Duplicate header",
+					Detail:        fmt.Sprintf("%s: the header is already defined as %q", attributePathString(path.IndexString(k)), other),
+					AttributePath: path.IndexString(k),
+				})
+				continue
+			}
+			keys[canonical] = k
+		}
+		return diags
+	}
+}
+
+// resourceHealthcheckDurationsCustomizeDiff checks that the health check timeout
+// is lower than the interval. The error is a cty.PathError so Terraform reports it
+// on the timeout attribute: the resources compose their CustomizeDiff functions with
+// customdiff.Sequence, which returns it unwrapped. The minimum interval of the platform is not checked
+// here: neither the API documentation nor the go-client state its value, so it
+// is left to the API validation, whose error is reported on the interval attribute.
+func resourceHealthcheckDurationsCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if !d.NewValueKnown(resHealthcheckInterval) || !d.NewValueKnown(resHealthcheckTimeout) {
+		return nil
+	}
+	intervalStr := d.Get(resHealthcheckInterval).(string)
+	timeoutStr := d.Get(resHealthcheckTimeout).(string)
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil {
+		return nil
+	}
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return nil
+	}
+	if timeout >= interval {
+		return cty.GetAttrPath(resHealthcheckTimeout).NewErrorf("the timeout (%s) should be lower than the interval (%s)", timeoutStr, intervalStr)
+	}
+	return nil
+}
+
+// resourceHealthcheckTimeoutsCustomizeDiff rejects the operation timeouts shorter than
+// the waits between the retries of the provider (max_retries waits of retry_max_wait):
+// such an operation would give up before its last attempt. The operations without
+// configured timeout use defaultTimeout.
+func resourceHealthcheckTimeoutsCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if meta == nil || GetAppclacksClient(meta) == nil {
+		return nil
+	}
+	waits := GetAppclacksClient(meta).maxRetryWaits()
+	timeouts := cty.NullVal(cty.DynamicPseudoType)
+	if config := d.GetRawConfig(); !config.IsNull() && config.IsKnown() && config.Type().HasAttribute(schema.TimeoutsConfigKey) {
+		timeouts = config.GetAttr(schema.TimeoutsConfigKey)
+	}
+	timeout := func(key string) (time.Duration, bool) {
+		if timeouts.IsNull() || !timeouts.IsKnown() || !timeouts.Type().HasAttribute(key) {
+			return defaultTimeout, false
+		}
+		v := timeouts.GetAttr(key)
+		if v.IsNull() || !v.IsKnown() {
+			return defaultTimeout, false
+		}
+		duration, err := time.ParseDuration(v.AsString())
+		if err != nil {
+			// invalid durations are reported by the SDK
+			return defaultTimeout, false
+		}
+		return duration, true
+	}
+	for _, key := range []string{schema.TimeoutCreate, schema.TimeoutRead, schema.TimeoutUpdate, schema.TimeoutDelete} {
+		duration, configured := timeout(key)
+		if duration > waits {
+			continue
+		}
+		message := fmt.Sprintf("the %s timeout (%s) should be longer than the waits between the retries of the provider (%s, max_retries waits of retry_max_wait)", key, duration, waits)
+		if !configured {
+			return fmt.Errorf("%s: configure a longer timeout in the %s block, or lower max_retries or retry_max_wait", message, schema.TimeoutsConfigKey)
+		}
+		return cty.GetAttrPath(schema.TimeoutsConfigKey).GetAttr(key).NewErrorf("%s", message)
+	}
+	return nil
+}
+
+// resourceHealthcheckTLSCustomizeDiff rejects the TLS settings which cannot work
+// (a cert without a key or a key without a cert). The settings ignored by the
+// prober are reported as warnings by the resource validation, see healthcheckTLSConfigWarnings.
+func resourceHealthcheckTLSCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+	certSet := !config.GetAttr(resHealthcheckTLSCert).IsNull()
+	keySet := !config.GetAttr(resHealthcheckTLSKey).IsNull()
+	if certSet && !keySet {
+		return cty.GetAttrPath(resHealthcheckTLSCert).NewErrorf("a %s should be configured when a %s is set", resHealthcheckTLSKey, resHealthcheckTLSCert)
+	}
+	if keySet && !certSet {
+		return cty.GetAttrPath(resHealthcheckTLSKey).NewErrorf("a %s should be configured when a %s is set", resHealthcheckTLSCert, resHealthcheckTLSKey)
+	}
+	return nil
+}
+
+// healthcheckTLSConfigWarnings returns a warning for each configured TLS setting
+// which is ignored by the prober: TLS settings on plain HTTP health checks, and
+// the certificate expiration delay when the certificate is not verified. Only the
+// configuration is checked, not the values set by the provider healthcheck_defaults.
+func healthcheckTLSConfigWarnings(healthcheckType string, config cty.Value) diag.Diagnostics {
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+	set := func(key string) bool {
+		v := config.GetAttr(key)
+		if !v.IsKnown() || v.IsNull() {
+			return false
+		}
+		if v.Type() == cty.Bool {
+			return v.True()
+		}
+		return true
+	}
+	var diags diag.Diagnostics
+	switch healthcheckType {
+	case "http":
+		protocol := config.GetAttr(resHealthcheckHTTPProtocol)
+		if !protocol.IsKnown() || protocol.IsNull() || protocol.AsString() != "http" {
+			return nil
+		}
+		for _, key := range []string{resHealthcheckTLSCert, resHealthcheckTLSKey, resHealthcheckTLSCacert, resHealthcheckTLSServerName, resHealthcheckTLSInsecure} {
+			if !set(key) {
+				continue
+			}
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Warning,
+				Summary:       "Ignored TLS setting",
+				Detail:        fmt.Sprintf("%s is ignored when the %s is http", key, resHealthcheckHTTPProtocol),
+				AttributePath: cty.GetAttrPath(key),
+			})
+		}
+	case "tls":
+		if set(resHealthcheckTLSExpirationDelay) && set(resHealthcheckTLSInsecure) {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Warning,
+				Summary:       "Ignored TLS setting",
+				Detail:        fmt.Sprintf("%s is ignored when %s is true", resHealthcheckTLSExpirationDelay, resHealthcheckTLSInsecure),
+				AttributePath: cty.GetAttrPath(resHealthcheckTLSExpirationDelay),
+			})
+		}
+	}
+	return diags
+}
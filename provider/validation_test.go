@@ -0,0 +1,458 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// testRawConfig builds the raw configuration of a resource
+func testRawConfig(t *testing.T, r *schema.Resource, raw map[string]interface{}) cty.Value {
+	t.Helper()
+	b, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := ctyjson.Unmarshal(b, r.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+// testResourceDiff computes the plan of a resource creation for the given configuration
+func testResourceDiff(t *testing.T, r *schema.Resource, raw map[string]interface{}, meta interface{}) (*terraform.InstanceDiff, error) {
+	t.Helper()
+	coreSchema := r.CoreConfigSchema()
+	config := testRawConfig(t, r, raw)
+	state := &terraform.InstanceState{
+		Attributes: map[string]string{},
+		RawConfig:  config,
+	}
+	return r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigShimmed(config, coreSchema), meta)
+}
+
+func TestValidateDuration(t *testing.T) {
+	valid := []string{"30s", "5m", "1h30m", "168h"}
+	for _, v := range valid {
+		if diags := validateDuration(v, cty.GetAttrPath(resHealthcheckInterval)); diags.HasError() {
+			t.Fatalf("expected %s to be valid, got %v", v, diags)
+		}
+	}
+	invalid := []string{"30 s", "5mins", "", "-5s", "0s"}
+	for _, v := range invalid {
+		diags := validateDuration(v, cty.GetAttrPath(resHealthcheckInterval))
+		if !diags.HasError() {
+			t.Fatalf("expected %s to be invalid", v)
+		}
+		if !diags[0].AttributePath.Equals(cty.GetAttrPath(resHealthcheckInterval)) {
+			t.Fatalf("unexpected attribute path %v", diags[0].AttributePath)
+		}
+	}
+}
+
+func TestResourceHealthcheckDurationsCustomizeDiff(t *testing.T) {
+	meta := &providerConfig{
+		healthcheckDefaults: expandHealthcheckDefaults(nil),
+	}
+	cases := []struct {
+		interval string
+		timeout  string
+		err      string
+	}{
+		{interval: "30s", timeout: "5s"},
+		{interval: "30s", timeout: "30s", err: "should be lower than the interval"},
+		{interval: "10s", timeout: "1m", err: "should be lower than the interval"},
+		{interval: "5s", timeout: "1s"},
+	}
+	for _, c := range cases {
+		_, err := testResourceDiff(t, resourceHealthcheckTCP(), map[string]interface{}{
+			resHealthcheckName:     "tf_acc_tcp",
+			resHealthcheckTarget:   "appclacks.com",
+			resHealthcheckPort:     443,
+			resHealthcheckInterval: c.interval,
+			resHealthcheckTimeout:  c.timeout,
+		}, meta)
+		if c.err == "" && err != nil {
+			t.Fatalf("unexpected error for %s/%s: %s", c.interval, c.timeout, err)
+		}
+		if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Fatalf("expected error %q for %s/%s, got %v", c.err, c.interval, c.timeout, err)
+		}
+		// the SDK only reports the path of the errors which are a cty.PathError, not wrapped
+		if pathErr, ok := err.(cty.PathError); c.err != "" && (!ok || !pathErr.Path.Equals(cty.GetAttrPath(resHealthcheckTimeout))) {
+			t.Fatalf("expected an error on %s, got %#v", resHealthcheckTimeout, err)
+		}
+	}
+}
+
+func TestResourceHealthcheckTimeoutsCustomizeDiff(t *testing.T) {
+	cases := []struct {
+		name         string
+		maxRetries   int
+		retryMaxWait time.Duration
+		timeouts     map[string]interface{}
+		err          string
+		path         cty.Path
+	}{
+		{
+			name:         "default settings",
+			maxRetries:   defaultMaxRetries,
+			retryMaxWait: 30 * time.Second,
+		},
+		{
+			name:         "default timeouts shorter than the retry waits",
+			maxRetries:   5,
+			retryMaxWait: time.Minute,
+			err:          "the create timeout (2m0s) should be longer than the waits between the retries of the provider (5m0s",
+		},
+		{
+			name:         "longer timeouts",
+			maxRetries:   5,
+			retryMaxWait: time.Minute,
+			timeouts:     map[string]interface{}{"create": "10m", "read": "10m", "update": "10m", "delete": "10m"},
+		},
+		{
+			name:         "configured timeout shorter than the retry waits",
+			maxRetries:   defaultMaxRetries,
+			retryMaxWait: 30 * time.Second,
+			timeouts:     map[string]interface{}{"read": "1m"},
+			err:          "the read timeout (1m0s) should be longer than the waits between the retries of the provider (1m30s",
+			path:         cty.GetAttrPath("timeouts").GetAttr("read"),
+		},
+		{
+			name:         "no retries",
+			maxRetries:   0,
+			retryMaxWait: time.Hour,
+			timeouts:     map[string]interface{}{"read": "1s"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				resHealthcheckName:     "tf_acc_tcp",
+				resHealthcheckTarget:   "appclacks.com",
+				resHealthcheckPort:     443,
+				resHealthcheckInterval: "60s",
+				resHealthcheckTimeout:  "10s",
+			}
+			if c.timeouts != nil {
+				raw["timeouts"] = c.timeouts
+			}
+			_, err := testResourceDiff(t, resourceHealthcheckTCP(), raw, &providerConfig{
+				client:              &Client{maxRetries: c.maxRetries, retryMaxWait: c.retryMaxWait},
+				healthcheckDefaults: expandHealthcheckDefaults(nil),
+			})
+			if c.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected error %q, got %v", c.err, err)
+			}
+			pathErr, ok := err.(cty.PathError)
+			if c.path == nil && ok {
+				t.Fatalf("expected an error without path, got %#v", err)
+			}
+			if c.path != nil && (!ok || !pathErr.Path.Equals(c.path)) {
+				t.Fatalf("expected an error on %#v, got %#v", c.path, err)
+			}
+		})
+	}
+}
+
+func TestResourceHealthcheckHTTPValidation(t *testing.T) {
+	headers := make(map[string]interface{})
+	for i := 0; i < 21; i++ {
+		headers[fmt.Sprintf("header-%d", i)] = "value"
+	}
+	cases := []struct {
+		name   string
+		config map[string]interface{}
+		err    string
+	}{
+		{
+			name:   "valid",
+			config: map[string]interface{}{},
+		},
+		{
+			name: "invalid method",
+			config: map[string]interface{}{
+				resHealthcheckHTTPMethod: "PATCH",
+			},
+			err: "expected method to be one of",
+		},
+		{
+			name: "invalid protocol",
+			config: map[string]interface{}{
+				resHealthcheckHTTPProtocol: "ftp",
+			},
+			err: "expected protocol to be one of",
+		},
+		{
+			name: "invalid status",
+			config: map[string]interface{}{
+				resHealthcheckHTTPValidStatus: []interface{}{200, 1001},
+			},
+			err: "to be in the range (1 - 1000)",
+		},
+		{
+			name: "too many body regexp",
+			config: map[string]interface{}{
+				resHealthcheckHTTPBodyRegexp: []interface{}{"a", "b", "c", "d"},
+			},
+			err: "supports 3 item maximum",
+		},
+		{
+			name: "invalid body regexp",
+			config: map[string]interface{}{
+				resHealthcheckHTTPBodyRegexp: []interface{}{"(foo"},
+			},
+			err: "error parsing regexp",
+		},
+		{
+			name: "too many headers",
+			config: map[string]interface{}{
+				resHealthcheckHTTPHeaders: headers,
+			},
+			err: "At most 20 elements are allowed",
+		},
+		{
+			name: "too many query parameters",
+			config: map[string]interface{}{
+				resHealthcheckHTTPQuery: headers,
+			},
+			err: "At most 20 elements are allowed",
+		},
+	}
+	for _, c := range cases {
+		config := map[string]interface{}{
+			resHealthcheckName:            "tf_acc_http",
+			resHealthcheckTarget:          "appclacks.com",
+			resHealthcheckPort:            443,
+			resHealthcheckHTTPValidStatus: []interface{}{200},
+		}
+		for k, v := range c.config {
+			config[k] = v
+		}
+		diags := resourceHealthcheckHTTP().Validate(terraform.NewResourceConfigRaw(config))
+		if c.err == "" {
+			if diags.HasError() {
+				t.Fatalf("%s: unexpected error %v", c.name, diags)
+			}
+			continue
+		}
+		found := false
+		for _, d := range diags {
+			if strings.Contains(d.Summary, c.err) || strings.Contains(d.Detail, c.err) {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("%s: expected error %q, got %v", c.name, c.err, diags)
+		}
+	}
+}
+
+func TestValidateTargets(t *testing.T) {
+	path := cty.GetAttrPath(resHealthcheckTarget)
+	for _, v := range []string{"appclacks.com", "api.appclacks.com.", "localhost", "192.168.1.1", "2001:db8::1", "my-host-1"} {
+		if diags := validateHostnameOrIP(v, path); diags.HasError() {
+			t.Fatalf("expected %s to be valid, got %v", v, diags)
+		}
+	}
+	for _, v := range []string{"", "http://appclacks.com", "appclacks.com:443", "-appclacks.com", "app clacks.com", "appclacks..com", strings.Repeat("a", 64) + ".com"} {
+		if diags := validateHostnameOrIP(v, path); !diags.HasError() {
+			t.Fatalf("expected %s to be invalid", v)
+		}
+	}
+
+	path = cty.GetAttrPath(resHealthcheckDNSDomain)
+	for _, v := range []string{"appclacks.com", "_dmarc.appclacks.com"} {
+		if diags := validateDomain(v, path); diags.HasError() {
+			t.Fatalf("expected %s to be valid, got %v", v, diags)
+		}
+	}
+	for _, v := range []string{"", "appclacks .com", "appclacks.com/foo"} {
+		if diags := validateDomain(v, path); !diags.HasError() {
+			t.Fatalf("expected %s to be invalid", v)
+		}
+	}
+}
+
+func TestValidateIPAddress(t *testing.T) {
+	path := cty.GetAttrPath(resHealthcheckDNSExpectedIPs).IndexInt(2)
+	if diags := validateIPAddress("10.0.0.1", path); diags.HasError() {
+		t.Fatalf("unexpected error %v", diags)
+	}
+	diags := validateIPAddress("foo", path)
+	if !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+	if diags[0].Detail != `expected_ips[2]: "foo" is not an IP address` {
+		t.Fatalf("unexpected error detail %s", diags[0].Detail)
+	}
+}
+
+func TestResourceHealthcheckDNSValidation(t *testing.T) {
+	ips := make([]interface{}, 0)
+	for i := 0; i < 11; i++ {
+		ips = append(ips, fmt.Sprintf("10.0.0.%d", i))
+	}
+	diags := resourceHealthcheckDNS().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		resHealthcheckName:           "tf_acc_dns",
+		resHealthcheckDNSDomain:      "appclacks.com",
+		resHealthcheckDNSExpectedIPs: ips,
+	}))
+	if !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+
+	diags = resourceHealthcheckDNS().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		resHealthcheckName:           "tf_acc_dns",
+		resHealthcheckDNSDomain:      "appclacks.com",
+		resHealthcheckDNSExpectedIPs: []interface{}{"10.0.0.1", "foo"},
+	}))
+	if !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(diags[0].Detail, `"foo" is not an IP address`) {
+		t.Fatalf("unexpected error %v", diags)
+	}
+}
+
+func TestResourceHealthcheckPortValidation(t *testing.T) {
+	for _, port := range []int{0, 65536} {
+		diags := resourceHealthcheckTCP().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+			resHealthcheckName:   "tf_acc_tcp",
+			resHealthcheckTarget: "appclacks.com",
+			resHealthcheckPort:   port,
+		}))
+		if !diags.HasError() {
+			t.Fatalf("expected port %d to be invalid", port)
+		}
+	}
+}
+
+func TestResourceHealthcheckTLSCustomizeDiff(t *testing.T) {
+	meta := &providerConfig{
+		healthcheckDefaults: expandHealthcheckDefaults(nil),
+	}
+	cases := []struct {
+		config map[string]interface{}
+		path   string
+		err    string
+	}{
+		{config: map[string]interface{}{resHealthcheckTLSCert: "/cert.pem", resHealthcheckTLSKey: "/key.pem"}},
+		{config: map[string]interface{}{resHealthcheckTLSCacert: "/ca.pem"}},
+		{config: map[string]interface{}{resHealthcheckTLSCert: "/cert.pem"}, path: resHealthcheckTLSCert, err: "a key should be configured"},
+		{config: map[string]interface{}{resHealthcheckTLSKey: "/key.pem"}, path: resHealthcheckTLSKey, err: "a cert should be configured"},
+	}
+	for _, r := range []*schema.Resource{resourceHealthcheckHTTP(), resourceHealthcheckTLS()} {
+		for _, c := range cases {
+			config := map[string]interface{}{
+				resHealthcheckName:   "tf_acc_tls",
+				resHealthcheckTarget: "appclacks.com",
+				resHealthcheckPort:   443,
+			}
+			for k, v := range c.config {
+				config[k] = v
+			}
+			_, err := testResourceDiff(t, r, config, meta)
+			if c.err == "" && err != nil {
+				t.Fatalf("unexpected error for %v: %s", c.config, err)
+			}
+			if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+				t.Fatalf("expected error %q for %v, got %v", c.err, c.config, err)
+			}
+			if pathErr, ok := err.(cty.PathError); c.err != "" && (!ok || !pathErr.Path.Equals(cty.GetAttrPath(c.path))) {
+				t.Fatalf("expected an error on %s, got %#v", c.path, err)
+			}
+		}
+	}
+}
+
+func TestHealthcheckTLSConfigWarnings(t *testing.T) {
+	cases := []struct {
+		healthcheckType string
+		resource        *schema.Resource
+		config          map[string]interface{}
+		warnings        []string
+	}{
+		{
+			healthcheckType: "http",
+			resource:        resourceHealthcheckHTTP(),
+			config: map[string]interface{}{
+				resHealthcheckHTTPProtocol:  "http",
+				resHealthcheckTLSServerName: "appclacks.com",
+				resHealthcheckTLSInsecure:   true,
+			},
+			warnings: []string{resHealthcheckTLSServerName, resHealthcheckTLSInsecure},
+		},
+		{
+			healthcheckType: "http",
+			resource:        resourceHealthcheckHTTP(),
+			config: map[string]interface{}{
+				resHealthcheckHTTPProtocol: "http",
+				resHealthcheckTLSInsecure:  false,
+			},
+		},
+		{
+			healthcheckType: "http",
+			resource:        resourceHealthcheckHTTP(),
+			config: map[string]interface{}{
+				resHealthcheckTLSServerName: "appclacks.com",
+				resHealthcheckTLSInsecure:   true,
+			},
+		},
+		{
+			healthcheckType: "tls",
+			resource:        resourceHealthcheckTLS(),
+			config: map[string]interface{}{
+				resHealthcheckTLSExpirationDelay: "168h",
+				resHealthcheckTLSInsecure:        true,
+			},
+			warnings: []string{resHealthcheckTLSExpirationDelay},
+		},
+		{
+			healthcheckType: "tls",
+			resource:        resourceHealthcheckTLS(),
+			config: map[string]interface{}{
+				resHealthcheckTLSExpirationDelay: "168h",
+			},
+		},
+	}
+	for _, c := range cases {
+		config := map[string]interface{}{
+			resHealthcheckName:   "tf_acc_tls",
+			resHealthcheckTarget: "appclacks.com",
+			resHealthcheckPort:   443,
+		}
+		for k, v := range c.config {
+			config[k] = v
+		}
+		diags := healthcheckTLSConfigWarnings(c.healthcheckType, testRawConfig(t, c.resource, config))
+		if len(diags) != len(c.warnings) {
+			t.Fatalf("expected warnings for %v, got %v", c.warnings, diags)
+		}
+		for i, warning := range c.warnings {
+			if diags[i].Severity != diag.Warning {
+				t.Fatalf("expected a warning, got %v", diags[i])
+			}
+			if !diags[i].AttributePath.Equals(cty.GetAttrPath(warning)) {
+				t.Fatalf("expected a warning for %s, got %v", warning, diags[i])
+			}
+		}
+	}
+}